@@ -0,0 +1,29 @@
+package resource
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/concourse/time-resource/ics"
+)
+
+// loadCalendars parses the iCalendar files at paths. Relative paths are
+// resolved against dir.
+func loadCalendars(dir string, paths []string, loc *time.Location) ([]*ics.Calendar, error) {
+	var calendars []*ics.Calendar
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		calendar, err := ics.ParseFile(path, loc)
+		if err != nil {
+			return nil, fmt.Errorf("loading calendar %s: %w", path, err)
+		}
+
+		calendars = append(calendars, calendar)
+	}
+
+	return calendars, nil
+}
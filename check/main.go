@@ -18,7 +18,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	command := resource.CheckCommand{}
+	clock, err := resource.ClockFromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error:", err.Error())
+		os.Exit(1)
+	}
+
+	command := resource.CheckCommand{Clock: clock}
 
 	versions, err := command.Run(request)
 	if err != nil {
@@ -26,5 +32,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	if request.Source.Debug && command.Decision.Reason != "" {
+		fmt.Fprintln(os.Stderr, "check:", command.Decision)
+	}
+
 	json.NewEncoder(os.Stdout).Encode(versions)
 }
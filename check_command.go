@@ -7,32 +7,47 @@ import (
 	"github.com/concourse/time-resource/models"
 )
 
+const DEFAULT_MAX_CATCH_UP = 100
+
 type CheckCommand struct {
+	// Clock tells the current time, defaulting to the system's clock
+	Clock Clock
+
+	// TimeLord is the schedule of the request's source, as built by
+	// NewTimeLord. If nil, it is built, and its calendars loaded, on every
+	// Run.
+	TimeLord *lord.TimeLord
+
+	// Decision is why the last Run did or did not produce a new version,
+	// unless it was decided by initial_version. With catch_up, it is the
+	// decision for the current time alone.
+	Decision lord.Decision
 }
 
-func (*CheckCommand) Run(request models.CheckRequest) ([]models.Version, error) {
+func (command *CheckCommand) Run(request models.CheckRequest) ([]models.Version, error) {
 	err := request.Source.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	previousTime := request.Version.Time
-	currentTime := time.Now().UTC()
+	currentTime := currentTime(command.Clock).UTC()
 
 	specifiedLocation := request.Source.Location
 	if specifiedLocation != nil {
 		currentTime = currentTime.In((*time.Location)(specifiedLocation))
 	}
 
-	tl := lord.TimeLord{
-		PreviousTime: previousTime,
-		Location:     specifiedLocation,
-		Start:        request.Source.Start,
-		Stop:         request.Source.Stop,
-		Interval:     request.Source.Interval,
-		Days:         request.Source.Days,
-		StartAfter:   request.Source.StartAfter,
+	var tl lord.TimeLord
+	if command.TimeLord != nil {
+		tl = *command.TimeLord
+	} else {
+		tl, err = timeLord(request.Source, currentTime.Location())
+		if err != nil {
+			return nil, err
+		}
 	}
+	tl.PreviousTime = previousTime
 
 	var versions []models.Version
 
@@ -43,9 +58,128 @@ func (*CheckCommand) Run(request models.CheckRequest) ([]models.Version, error)
 		return versions, nil
 	}
 
-	if tl.Check(currentTime) {
-		versions = append(versions, models.Version{Time: currentTime})
+	// With spread, the whole schedule is shifted later by the pipeline's
+	// offset; evaluate it in the shifted frame and shift the results back.
+	var spread time.Duration
+	checkTime := currentTime
+	if request.Source.Spread {
+		spread = spreadOffset(tl, currentTime)
+
+		checkTime = currentTime.Add(-spread)
+		if !previousTime.IsZero() {
+			tl.PreviousTime = previousTime.Add(-spread)
+		}
+	}
+
+	command.Decision = tl.Check(checkTime)
+
+	if request.Source.CatchUp && !previousTime.IsZero() {
+		return append(versions, catchUp(tl, checkTime, request.Source.Spread, request.Source.MaxCatchUp)...), nil
+	}
+
+	if command.Decision.Fire {
+		versionTime := currentTime
+		if request.Source.Aligned || request.Source.Spread {
+			scheduled := tl.Latest(checkTime)
+			if scheduled.IsZero() || !scheduled.After(tl.PreviousTime) {
+				command.Decision = lord.Decision{Reason: lord.ALREADY_FIRED, Range: command.Decision.Range}
+				return versions, nil
+			}
+			versionTime = scheduled.Add(spread)
+		}
+
+		versions = append(versions, models.Version{Time: versionTime})
 	}
 
 	return versions, nil
 }
+
+// catchUp returns a version for every scheduled time after the previous
+// version, keeping only the most recent maxCatchUp of them. With spread, each
+// version is shifted later by the offset of its own range.
+func catchUp(tl lord.TimeLord, checkTime time.Time, spread bool, maxCatchUp int) []models.Version {
+	if maxCatchUp == 0 {
+		maxCatchUp = DEFAULT_MAX_CATCH_UP
+	}
+
+	var missed []models.Version
+	for _, scheduled := range tl.List(checkTime) {
+		if !scheduled.After(tl.PreviousTime) {
+			continue
+		}
+
+		if spread {
+			scheduled = scheduled.Add(rangeOffset(tl, scheduled))
+		}
+		missed = append(missed, models.Version{Time: scheduled})
+	}
+
+	if len(missed) > maxCatchUp {
+		missed = missed[len(missed)-maxCatchUp:]
+	}
+
+	return missed
+}
+
+// NewTimeLord returns a TimeLord for the schedule described by source, with
+// its calendars loaded, for checking the same source repeatedly.
+func NewTimeLord(source models.Source) (lord.TimeLord, error) {
+	loc := time.UTC
+	if source.Location != nil {
+		loc = (*time.Location)(source.Location)
+	}
+
+	return timeLord(source, loc)
+}
+
+// timeLord returns a TimeLord for the schedule described by source, with its
+// calendars loaded in loc.
+func timeLord(source models.Source, loc *time.Location) (lord.TimeLord, error) {
+	blackoutCalendars, err := loadCalendars("", source.BlackoutCalendars, loc)
+	if err != nil {
+		return lord.TimeLord{}, err
+	}
+
+	allowCalendars, err := loadCalendars("", source.AllowCalendars, loc)
+	if err != nil {
+		return lord.TimeLord{}, err
+	}
+
+	var latitude, longitude float64
+	if source.Latitude != nil && source.Longitude != nil {
+		latitude = *source.Latitude
+		longitude = *source.Longitude
+	}
+
+	return lord.TimeLord{
+		Location:       source.Location,
+		Latitude:       latitude,
+		Longitude:      longitude,
+		Start:          source.Start,
+		Stop:           source.Stop,
+		Interval:       source.Interval,
+		IntervalAnchor: source.IntervalAnchor,
+		Windows:        source.Windows,
+		Schedule:       source.Schedule,
+		Blackouts:      source.Blackouts,
+		Days:           source.Days,
+		DaysOfMonth:    source.DaysOfMonth,
+		BusinessDays:   source.BusinessDaysOfMonth,
+		Months:         source.Months,
+		Weeks:          source.Weeks,
+		WeekParity:     source.WeekParity,
+		ExcludeDates:   source.ExcludeDates,
+		SkipHolidays:   source.SkipHolidays,
+		StartAfter:     source.StartAfter,
+		StopAfter:      source.StopAfter,
+		EveryNDays:     source.EveryNDays,
+		EveryNWeeks:    source.EveryNWeeks,
+		Anchor:         source.Anchor,
+		DSTPolicy:      source.DSTPolicy,
+		Cron:           source.Cron,
+		RRule:          source.RRule,
+
+		BlackoutCalendars: blackoutCalendars,
+		AllowCalendars:    allowCalendars,
+	}, nil
+}
@@ -1,9 +1,13 @@
 package resource_test
 
 import (
+	"os"
+	"path/filepath"
 	"time"
 
 	resource "github.com/concourse/time-resource"
+	"github.com/concourse/time-resource/cron"
+	"github.com/concourse/time-resource/lord"
 	"github.com/concourse/time-resource/models"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -515,6 +519,339 @@ var _ = Describe("Check", func() {
 			})
 		})
 
+		Context("when a cron expression is specified", func() {
+			BeforeEach(func() {
+				schedule, err := cron.Parse("* * * * *")
+				Expect(err).NotTo(HaveOccurred())
+				source.Cron = (*models.Cron)(schedule)
+			})
+
+			Context("when a version is given", func() {
+				var prev time.Time
+
+				Context("with its time after the latest firing", func() {
+					BeforeEach(func() {
+						prev = now
+						version.Time = prev
+					})
+
+					It("outputs a supplied version", func() {
+						Expect(response).To(HaveLen(1))
+						Expect(response[0].Time.Unix()).To(Equal(prev.Unix()))
+					})
+				})
+
+				Context("with its time before the latest firing", func() {
+					BeforeEach(func() {
+						prev = now.Add(-2 * time.Minute)
+						version.Time = prev
+					})
+
+					It("outputs a version containing the current time and supplied version", func() {
+						Expect(response).To(HaveLen(2))
+						Expect(response[0].Time.Unix()).To(Equal(prev.Unix()))
+						Expect(response[1].Time.Unix()).To(BeNumerically("~", time.Now().Unix(), 1))
+					})
+				})
+			})
+		})
+
+		Context("when catch_up is specified", func() {
+			BeforeEach(func() {
+				interval := models.Interval(time.Minute)
+				source.Interval = &interval
+				source.CatchUp = true
+			})
+
+			Context("when no version is given", func() {
+				It("outputs a version containing the current time", func() {
+					Expect(response).To(HaveLen(1))
+					Expect(response[0].Time.Unix()).To(BeNumerically("~", time.Now().Unix(), 1))
+				})
+			})
+
+			Context("when a version is given", func() {
+				var prev time.Time
+
+				Context("with its time within the interval", func() {
+					BeforeEach(func() {
+						prev = now
+						version.Time = prev
+					})
+
+					It("outputs a supplied version", func() {
+						Expect(response).To(HaveLen(1))
+						Expect(response[0].Time.Unix()).To(Equal(prev.Unix()))
+					})
+				})
+
+				Context("with its time N intervals ago", func() {
+					BeforeEach(func() {
+						prev = now.Add(-5 * time.Minute)
+						version.Time = prev
+					})
+
+					It("outputs the supplied version and every missed interval", func() {
+						Expect(response).To(HaveLen(6))
+						Expect(response[0].Time.Unix()).To(Equal(prev.Unix()))
+						for i, missed := range response[1:] {
+							Expect(missed.Time).To(Equal(prev.Truncate(time.Minute).Add(time.Duration(i+1) * time.Minute)))
+						}
+					})
+
+					Context("when max_catch_up is specified", func() {
+						BeforeEach(func() {
+							source.MaxCatchUp = 2
+						})
+
+						It("outputs the supplied version and the most recent missed intervals", func() {
+							Expect(response).To(HaveLen(3))
+							Expect(response[0].Time.Unix()).To(Equal(prev.Unix()))
+							Expect(response[1].Time).To(Equal(prev.Truncate(time.Minute).Add(4 * time.Minute)))
+							Expect(response[2].Time).To(Equal(prev.Truncate(time.Minute).Add(5 * time.Minute)))
+						})
+					})
+				})
+
+				Context("with a time range", func() {
+					BeforeEach(func() {
+						source.Interval = nil
+						source.Start = tod(0, 0, 0)
+						source.Stop = tod(23, 59, 0)
+
+						prev = now.Add(-72 * time.Hour)
+						version.Time = prev
+					})
+
+					It("outputs the supplied version and the start of every missed range", func() {
+						midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+						Expect(response).To(HaveLen(4))
+						Expect(response[0].Time.Unix()).To(Equal(prev.Unix()))
+						Expect(response[1].Time.Equal(midnight.AddDate(0, 0, -2))).To(BeTrue())
+						Expect(response[2].Time.Equal(midnight.AddDate(0, 0, -1))).To(BeTrue())
+						Expect(response[3].Time.Equal(midnight)).To(BeTrue())
+					})
+				})
+			})
+		})
+
+		Context("when aligned is specified", func() {
+			BeforeEach(func() {
+				source.Aligned = true
+			})
+
+			runAgain := func() models.CheckResponse {
+				command := resource.CheckCommand{}
+
+				again, err := command.Run(models.CheckRequest{
+					Source:  source,
+					Version: version,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				return again
+			}
+
+			Context("when an interval is specified", func() {
+				BeforeEach(func() {
+					interval := models.Interval(time.Hour)
+					source.Interval = &interval
+				})
+
+				Context("when no version is given", func() {
+					It("outputs a version containing the latest interval boundary", func() {
+						Expect(response).To(HaveLen(1))
+						Expect(response[0].Time.Equal(time.Now().Truncate(time.Hour))).To(BeTrue())
+						Expect(runAgain()).To(Equal(response))
+					})
+				})
+
+				Context("when a version is given N intervals ago", func() {
+					var prev time.Time
+
+					BeforeEach(func() {
+						prev = now.Add(-5 * time.Hour).Truncate(time.Hour)
+						version.Time = prev
+					})
+
+					It("outputs the supplied version and the latest interval boundary", func() {
+						Expect(response).To(HaveLen(2))
+						Expect(response[0].Time.Unix()).To(Equal(prev.Unix()))
+						Expect(response[1].Time.Equal(time.Now().Truncate(time.Hour))).To(BeTrue())
+					})
+				})
+
+				Context("when the latest interval boundary has already been emitted", func() {
+					BeforeEach(func() {
+						version.Time = now.Truncate(time.Hour)
+					})
+
+					It("outputs a supplied version", func() {
+						Expect(response).To(HaveLen(1))
+						Expect(response[0].Time.Equal(version.Time)).To(BeTrue())
+					})
+				})
+			})
+
+			Context("when a time range is specified", func() {
+				var start time.Time
+
+				BeforeEach(func() {
+					start = now.Add(-1 * time.Hour)
+					stop := now.Add(1 * time.Hour)
+
+					source.Start = tod(start.Hour(), start.Minute(), 0)
+					source.Stop = tod(stop.Hour(), stop.Minute(), 0)
+				})
+
+				It("outputs a version containing the start of the range", func() {
+					Expect(response).To(HaveLen(1))
+					Expect(response[0].Time.Equal(start.Truncate(time.Minute))).To(BeTrue())
+					Expect(runAgain()).To(Equal(response))
+				})
+			})
+		})
+
+		Context("when spread is specified", func() {
+			originalTeam := os.Getenv(resource.BUILD_TEAM_NAME)
+			originalPipeline := os.Getenv(resource.BUILD_PIPELINE_NAME)
+			originalPipelineInstanceVars := os.Getenv(resource.BUILD_PIPELINE_INSTANCE_VARS)
+
+			var expected time.Time
+
+			runAgain := func(version models.Version) models.CheckResponse {
+				command := resource.CheckCommand{}
+
+				again, err := command.Run(models.CheckRequest{
+					Source:  source,
+					Version: version,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				return again
+			}
+
+			BeforeEach(func() {
+				os.Setenv(resource.BUILD_TEAM_NAME, smallOffset.teamName)
+				os.Setenv(resource.BUILD_PIPELINE_NAME, smallOffset.pipelineName)
+				os.Setenv(resource.BUILD_PIPELINE_INSTANCE_VARS, smallOffset.pipelineInstanceVars)
+
+				source.Spread = true
+			})
+
+			AfterEach(func() {
+				os.Setenv(resource.BUILD_TEAM_NAME, originalTeam)
+				os.Setenv(resource.BUILD_PIPELINE_NAME, originalPipeline)
+				os.Setenv(resource.BUILD_PIPELINE_INSTANCE_VARS, originalPipelineInstanceVars)
+			})
+
+			Context("when an interval is specified", func() {
+				BeforeEach(func() {
+					interval := models.Interval(time.Hour)
+					source.Interval = &interval
+
+					offset := time.Duration(time.Hour.Minutes()*smallOffset.hashPercentile) * time.Minute
+					expected = now.Add(-offset).Truncate(time.Hour).Add(offset)
+				})
+
+				Context("when no version is given", func() {
+					It("outputs a version at the offset interval boundary", func() {
+						Expect(response).To(HaveLen(1))
+						Expect(response[0].Time.Equal(expected)).To(BeTrue())
+					})
+
+					It("outputs the same version on repeated checks", func() {
+						Expect(runAgain(models.Version{})).To(Equal(response))
+						Expect(runAgain(models.Version{})).To(Equal(response))
+					})
+
+					It("outputs no new version when given the version it produced", func() {
+						again := runAgain(response[0])
+						Expect(again).To(HaveLen(1))
+						Expect(again[0].Time.Equal(response[0].Time)).To(BeTrue())
+					})
+				})
+
+				Context("when a version is given N intervals ago", func() {
+					BeforeEach(func() {
+						version.Time = expected.Add(-5 * time.Hour)
+					})
+
+					It("outputs the supplied version and the latest offset interval boundary", func() {
+						Expect(response).To(HaveLen(2))
+						Expect(response[0].Time.Equal(version.Time)).To(BeTrue())
+						Expect(response[1].Time.Equal(expected)).To(BeTrue())
+					})
+
+					Context("when catch_up is specified", func() {
+						BeforeEach(func() {
+							source.CatchUp = true
+						})
+
+						It("outputs every missed offset interval boundary", func() {
+							Expect(response).To(HaveLen(6))
+							for i, missed := range response[1:] {
+								Expect(missed.Time.Equal(expected.Add(time.Duration(i-4) * time.Hour))).To(BeTrue())
+							}
+						})
+					})
+				})
+			})
+
+			Context("when a time range is specified", func() {
+				BeforeEach(func() {
+					start := now.Add(-3 * time.Hour)
+					stop := now.Add(3 * time.Hour)
+
+					source.Start = tod(start.Hour(), start.Minute(), 0)
+					source.Stop = tod(stop.Hour(), stop.Minute(), 0)
+
+					offset := time.Duration((6*time.Hour).Minutes()*smallOffset.hashPercentile) * time.Minute
+					expected = start.Truncate(time.Minute).Add(offset)
+				})
+
+				It("outputs a version at the offset start of the range", func() {
+					Expect(response).To(HaveLen(1))
+					Expect(response[0].Time.Equal(expected)).To(BeTrue())
+					Expect(runAgain(models.Version{})).To(Equal(response))
+				})
+			})
+		})
+
+		Context("when a blackout calendar is specified", func() {
+			var calendarDir string
+
+			BeforeEach(func() {
+				var err error
+				calendarDir, err = os.MkdirTemp("", "check-calendar")
+				Expect(err).NotTo(HaveOccurred())
+
+				content := "BEGIN:VCALENDAR\n" +
+					"BEGIN:VEVENT\n" +
+					"DTSTART:" + now.Add(-time.Hour).Format("20060102T150405Z") + "\n" +
+					"DTEND:" + now.Add(time.Hour).Format("20060102T150405Z") + "\n" +
+					"END:VEVENT\n" +
+					"END:VCALENDAR\n"
+
+				calendarPath := filepath.Join(calendarDir, "freeze.ics")
+				err = os.WriteFile(calendarPath, []byte(content), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				source.BlackoutCalendars = []string{calendarPath}
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(calendarDir)
+			})
+
+			Context("when no version is given and the current time is within an event", func() {
+				It("does not output any versions", func() {
+					Expect(response).To(BeEmpty())
+				})
+			})
+		})
+
 		Context("when start_after is specified", func() {
 			Context("when no version is provided", func() {
 				Context("and the current time is after start_after", func() {
@@ -616,6 +953,239 @@ var _ = Describe("Check", func() {
 	})
 })
 
+var _ = Describe("Check decision", func() {
+	It("records why no version was produced", func() {
+		tomorrow := models.Weekday(time.Now().UTC().AddDate(0, 0, 1).Weekday())
+
+		command := resource.CheckCommand{}
+		response, err := command.Run(models.CheckRequest{
+			Source: models.Source{Days: []models.Weekday{tomorrow}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response).To(BeEmpty())
+		Expect(command.Decision.Fire).To(BeFalse())
+		Expect(command.Decision.Reason).To(Equal(lord.DAY_NOT_MATCHED))
+		Expect(command.Decision.String()).To(Equal("skip: day not matched"))
+	})
+
+	It("records why a version was produced", func() {
+		interval := models.Interval(time.Hour)
+
+		command := resource.CheckCommand{}
+		response, err := command.Run(models.CheckRequest{
+			Version: models.Version{Time: time.Now().Add(-2 * time.Hour)},
+			Source:  models.Source{Interval: &interval},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response).To(HaveLen(2))
+		Expect(command.Decision.Fire).To(BeTrue())
+		Expect(command.Decision.Reason).To(Equal(lord.INTERVAL_ELAPSED))
+	})
+})
+
+var _ = Describe("Check with a clock", func() {
+	var clock resource.FixedClock
+
+	BeforeEach(func() {
+		clock = resource.FixedClock(time.Date(2018, 1, 8, 10, 30, 0, 0, time.UTC))
+	})
+
+	It("takes the current time from the clock", func() {
+		command := resource.CheckCommand{Clock: clock}
+		response, err := command.Run(models.CheckRequest{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response).To(Equal([]models.Version{{Time: time.Time(clock)}}))
+	})
+
+	It("decides whether to fire at the clock's time", func() {
+		start := models.NewTimeOfDay(time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC))
+		stop := models.NewTimeOfDay(time.Date(0, 1, 1, 11, 0, 0, 0, time.UTC))
+		previous := time.Date(2018, 1, 7, 10, 15, 0, 0, time.UTC)
+
+		command := resource.CheckCommand{Clock: clock}
+		response, err := command.Run(models.CheckRequest{
+			Version: models.Version{Time: previous},
+			Source:  models.Source{Start: &start, Stop: &stop},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response).To(Equal([]models.Version{{Time: previous}, {Time: time.Time(clock)}}))
+	})
+
+	It("checks against the given TimeLord rather than building one", func() {
+		source := models.Source{BlackoutCalendars: []string{"/nonexistent/freeze.ics"}}
+
+		_, err := resource.NewTimeLord(source)
+		Expect(err).To(HaveOccurred())
+
+		tl, err := resource.NewTimeLord(models.Source{})
+		Expect(err).NotTo(HaveOccurred())
+
+		command := resource.CheckCommand{Clock: clock, TimeLord: &tl}
+		response, err := command.Run(models.CheckRequest{Source: source})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response).To(Equal([]models.Version{{Time: time.Time(clock)}}))
+	})
+
+	It("catches up on interval times counted from the start of the range", func() {
+		interval := models.Interval(time.Hour)
+		start := models.NewTimeOfDay(time.Date(0, 1, 1, 7, 5, 0, 0, time.UTC))
+		stop := models.NewTimeOfDay(time.Date(0, 1, 1, 13, 0, 0, 0, time.UTC))
+		previous := time.Date(2018, 1, 8, 7, 5, 0, 0, time.UTC)
+
+		command := resource.CheckCommand{Clock: clock}
+		response, err := command.Run(models.CheckRequest{
+			Version: models.Version{Time: previous},
+			Source: models.Source{
+				Interval: &interval,
+				Start:    &start,
+				Stop:     &stop,
+				Aligned:  true,
+				CatchUp:  true,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response).To(Equal([]models.Version{
+			{Time: previous},
+			{Time: time.Date(2018, 1, 8, 8, 5, 0, 0, time.UTC)},
+			{Time: time.Date(2018, 1, 8, 9, 5, 0, 0, time.UTC)},
+			{Time: time.Date(2018, 1, 8, 10, 5, 0, 0, time.UTC)},
+		}))
+	})
+})
+
+var _ = Describe("Check with a blackout covering the start of a window", func() {
+	var source models.Source
+	var previous models.Version
+	var response models.CheckResponse
+
+	BeforeEach(func() {
+		start := models.NewTimeOfDay(time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC))
+		stop := models.NewTimeOfDay(time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC))
+		blackoutStop := models.NewTimeOfDay(time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC))
+
+		source = models.Source{
+			Start:     &start,
+			Stop:      &stop,
+			Blackouts: []models.Blackout{{Start: &start, Stop: &blackoutStop}},
+		}
+		previous = models.Version{Time: time.Date(2018, 1, 6, 9, 0, 0, 0, time.UTC)}
+	})
+
+	JustBeforeEach(func() {
+		command := resource.CheckCommand{Clock: resource.FixedClock(time.Date(2018, 1, 7, 9, 30, 0, 0, time.UTC))}
+
+		var err error
+		response, err = command.Run(models.CheckRequest{Source: source, Version: previous})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("outputs a version once the blackout ends", func() {
+		Expect(response).To(Equal(models.CheckResponse{previous, {Time: time.Date(2018, 1, 7, 9, 30, 0, 0, time.UTC)}}))
+	})
+
+	Context("when aligned is specified", func() {
+		BeforeEach(func() {
+			source.Aligned = true
+		})
+
+		It("outputs a version at the end of the blackout", func() {
+			Expect(response).To(Equal(models.CheckResponse{previous, {Time: time.Date(2018, 1, 7, 9, 0, 0, 0, time.UTC)}}))
+		})
+	})
+
+	Context("when catch_up is specified", func() {
+		BeforeEach(func() {
+			source.CatchUp = true
+		})
+
+		It("catches up on the end of the blackout", func() {
+			Expect(response).To(Equal(models.CheckResponse{previous, {Time: time.Date(2018, 1, 7, 9, 0, 0, 0, time.UTC)}}))
+		})
+	})
+})
+
+var _ = Describe("Check with spread at a fixed time", func() {
+	originalTeam := os.Getenv(resource.BUILD_TEAM_NAME)
+	originalPipeline := os.Getenv(resource.BUILD_PIPELINE_NAME)
+	originalPipelineInstanceVars := os.Getenv(resource.BUILD_PIPELINE_INSTANCE_VARS)
+
+	BeforeEach(func() {
+		os.Setenv(resource.BUILD_TEAM_NAME, smallOffset.teamName)
+		os.Setenv(resource.BUILD_PIPELINE_NAME, smallOffset.pipelineName)
+		os.Setenv(resource.BUILD_PIPELINE_INSTANCE_VARS, smallOffset.pipelineInstanceVars)
+	})
+
+	AfterEach(func() {
+		os.Setenv(resource.BUILD_TEAM_NAME, originalTeam)
+		os.Setenv(resource.BUILD_PIPELINE_NAME, originalPipeline)
+		os.Setenv(resource.BUILD_PIPELINE_INSTANCE_VARS, originalPipelineInstanceVars)
+	})
+
+	It("outputs one version a day, shifted by the same offset", func() {
+		loc, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+
+		location := models.Location(*loc)
+		source := models.Source{Spread: true, Location: &location}
+
+		// the clocks go forward on 2026-03-29
+		var versions []time.Time
+		version := models.Version{Time: time.Date(2026, 3, 27, 12, 0, 0, 0, loc)}
+		for now := time.Date(2026, 3, 28, 0, 0, 0, 0, loc); now.Before(time.Date(2026, 3, 31, 0, 0, 0, 0, loc)); now = now.Add(5 * time.Minute) {
+			command := resource.CheckCommand{Clock: resource.FixedClock(now)}
+			response, err := command.Run(models.CheckRequest{Source: source, Version: version})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, produced := range response[1:] {
+				versions = append(versions, produced.Time)
+				version = produced
+			}
+		}
+
+		offset := time.Duration((24*time.Hour).Minutes()*smallOffset.hashPercentile) * time.Minute
+		Expect(versions).To(HaveLen(3))
+		Expect(versions[0].Equal(time.Date(2026, 3, 28, 0, 0, 0, 0, loc).Add(offset))).To(BeTrue())
+		Expect(versions[1].Equal(time.Date(2026, 3, 29, 0, 0, 0, 0, loc).Add(offset))).To(BeTrue())
+		Expect(versions[2].Equal(time.Date(2026, 3, 30, 0, 0, 0, 0, loc).Add(offset))).To(BeTrue())
+	})
+
+	It("catches up on each range at the offset of that range", func() {
+		monday := models.NewTimeOfDay(time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC))
+		mondayStop := models.NewTimeOfDay(time.Date(0, 1, 1, 18, 0, 0, 0, time.UTC))
+		tuesday := models.NewTimeOfDay(time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC))
+		tuesdayStop := models.NewTimeOfDay(time.Date(0, 1, 1, 11, 0, 0, 0, time.UTC))
+
+		source := models.Source{
+			Spread:  true,
+			CatchUp: true,
+			Schedule: models.Schedule{
+				models.Weekday(time.Monday):  {Start: &monday, Stop: &mondayStop},
+				models.Weekday(time.Tuesday): {Start: &tuesday, Stop: &tuesdayStop},
+			},
+		}
+
+		previous := models.Version{Time: time.Date(2018, 1, 7, 12, 0, 0, 0, time.UTC)}
+
+		command := resource.CheckCommand{Clock: resource.FixedClock(time.Date(2018, 1, 9, 10, 40, 0, 0, time.UTC))}
+		response, err := command.Run(models.CheckRequest{Source: source, Version: previous})
+		Expect(err).NotTo(HaveOccurred())
+
+		mondayOffset := time.Duration((10*time.Hour).Minutes()*smallOffset.hashPercentile) * time.Minute
+		tuesdayOffset := time.Duration(time.Hour.Minutes()*smallOffset.hashPercentile) * time.Minute
+		Expect(response).To(Equal([]models.Version{
+			previous,
+			{Time: time.Date(2018, 1, 8, 8, 0, 0, 0, time.UTC).Add(mondayOffset)},
+			{Time: time.Date(2018, 1, 9, 10, 0, 0, 0, time.UTC).Add(tuesdayOffset)},
+		}))
+	})
+})
+
 func tod(hours, minutes, offset int) *models.TimeOfDay {
 	loc := time.UTC
 	if offset != 0 {
@@ -0,0 +1,54 @@
+package resource
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// TIME_RESOURCE_NOW, if set, is the RFC 3339 time the commands take to be
+// the current time, for reproducible end-to-end testing.
+const TIME_RESOURCE_NOW = "TIME_RESOURCE_NOW"
+
+// Clock tells the commands the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the system's clock, used by the commands by default.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always tells the same time.
+type FixedClock time.Time
+
+func (clock FixedClock) Now() time.Time {
+	return time.Time(clock)
+}
+
+// ClockFromEnv returns a FixedClock at TIME_RESOURCE_NOW if it is set, or
+// the system's clock otherwise.
+func ClockFromEnv() (Clock, error) {
+	now := os.Getenv(TIME_RESOURCE_NOW)
+	if now == "" {
+		return SystemClock{}, nil
+	}
+
+	fixed, err := time.Parse(time.RFC3339Nano, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s, must be an RFC 3339 time", TIME_RESOURCE_NOW, now)
+	}
+
+	return FixedClock(fixed), nil
+}
+
+func currentTime(clock Clock) time.Time {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	return clock.Now()
+}
@@ -0,0 +1,48 @@
+package resource_test
+
+import (
+	"os"
+	"time"
+
+	resource "github.com/concourse/time-resource"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClockFromEnv", func() {
+	AfterEach(func() {
+		os.Unsetenv(resource.TIME_RESOURCE_NOW)
+	})
+
+	Context("when TIME_RESOURCE_NOW is not set", func() {
+		It("returns the system's clock", func() {
+			clock, err := resource.ClockFromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clock).To(Equal(resource.SystemClock{}))
+			Expect(clock.Now().Unix()).To(BeNumerically("~", time.Now().Unix(), 1))
+		})
+	})
+
+	Context("when TIME_RESOURCE_NOW is set", func() {
+		BeforeEach(func() {
+			os.Setenv(resource.TIME_RESOURCE_NOW, "2018-01-08T10:30:00-05:00")
+		})
+
+		It("returns a clock fixed at that time", func() {
+			clock, err := resource.ClockFromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clock.Now().Equal(time.Date(2018, 1, 8, 15, 30, 0, 0, time.UTC))).To(BeTrue())
+		})
+	})
+
+	Context("when TIME_RESOURCE_NOW is not an RFC 3339 time", func() {
+		BeforeEach(func() {
+			os.Setenv(resource.TIME_RESOURCE_NOW, "tomorrow")
+		})
+
+		It("returns an error", func() {
+			_, err := resource.ClockFromEnv()
+			Expect(err).To(MatchError("invalid TIME_RESOURCE_NOW: tomorrow, must be an RFC 3339 time"))
+		})
+	})
+})
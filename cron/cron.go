@@ -0,0 +1,295 @@
+package cron
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchDays bounds how far Next and Prev will look for a matching day,
+// so that expressions which can never match (e.g. "0 0 30 2 *") terminate.
+const maxSearchDays = 366 * 5
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = field{name: "second", min: 0, max: 59}
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: monthNames}
+	dowField    = field{name: "day of week", min: 0, max: 7, names: dayNames}
+)
+
+// Schedule is a parsed cron expression. Each field is stored as a bitset of
+// the values it matches.
+type Schedule struct {
+	spec string
+
+	second, minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar record whether the day-of-month and day-of-week
+	// fields were unrestricted. As in standard cron, when both are
+	// restricted a day matches if either of them matches.
+	domStar, dowStar bool
+}
+
+// Parse parses a standard 5-field cron expression (minute, hour, day of
+// month, month, day of week), a 6-field expression with a leading seconds
+// field, or one of the @yearly, @monthly, @weekly, @daily and @hourly
+// macros.
+func Parse(spec string) (*Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	if strings.HasPrefix(expr, "@") {
+		expanded, found := macros[strings.ToLower(expr)]
+		if !found {
+			return nil, fmt.Errorf("unknown cron macro: %s", expr)
+		}
+		expr = expanded
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid cron expression: %q, expected 5 or 6 fields but got %d", spec, len(fields))
+	}
+
+	s := &Schedule{spec: strings.TrimSpace(spec)}
+
+	var err error
+	for i, f := range []struct {
+		field field
+		dest  *uint64
+	}{
+		{secondField, &s.second},
+		{minuteField, &s.minute},
+		{hourField, &s.hour},
+		{domField, &s.dom},
+		{monthField, &s.month},
+		{dowField, &s.dow},
+	} {
+		*f.dest, err = parseField(fields[i], f.field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %q: %w", spec, err)
+		}
+	}
+
+	// 7 is an alias for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	s.domStar = isStar(fields[3])
+	s.dowStar = isStar(fields[5])
+
+	return s, nil
+}
+
+func isStar(expr string) bool {
+	return expr == "*" || expr == "?"
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(expr, ",") {
+		bitsForPart, err := parsePart(part, f)
+		if err != nil {
+			return 0, err
+		}
+		set |= bitsForPart
+	}
+	return set, nil
+}
+
+func parsePart(part string, f field) (uint64, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepExpr)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step in %s field: %q", f.name, part)
+		}
+	}
+
+	var low, high int
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+		low, high = f.min, f.max
+		if f.name == dowField.name {
+			high = 6
+		}
+	case strings.Contains(rangeExpr, "-"):
+		lowExpr, highExpr, _ := strings.Cut(rangeExpr, "-")
+
+		var err error
+		low, err = parseValue(lowExpr, f)
+		if err != nil {
+			return 0, err
+		}
+		high, err = parseValue(highExpr, f)
+		if err != nil {
+			return 0, err
+		}
+		if high < low {
+			return 0, fmt.Errorf("invalid range in %s field: %q", f.name, part)
+		}
+	default:
+		var err error
+		low, err = parseValue(rangeExpr, f)
+		if err != nil {
+			return 0, err
+		}
+		high = low
+		if hasStep {
+			high = f.max
+		}
+	}
+
+	var set uint64
+	for v := low; v <= high; v += step {
+		set |= 1 << uint(v)
+	}
+	return set, nil
+}
+
+func parseValue(expr string, f field) (int, error) {
+	if v, found := f.names[strings.ToLower(expr)]; found {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value in %s field: %q", f.name, expr)
+	}
+
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s %d out of range [%d-%d]", f.name, v, f.min, f.max)
+	}
+
+	return v, nil
+}
+
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// Matches reports whether t, interpreted in its own location, satisfies
+// every field of the schedule.
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.dayMatches(t) &&
+		has(s.hour, t.Hour()) &&
+		has(s.minute, t.Minute()) &&
+		has(s.second, t.Second())
+}
+
+// Next returns the earliest scheduled time strictly after t, evaluated in
+// t's location. It returns the zero time if nothing matches within the next
+// few years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for i := 0; i < maxSearchDays; i++ {
+		if s.dayMatches(day) {
+			for _, h := range members(s.hour) {
+				for _, m := range members(s.minute) {
+					for _, sec := range members(s.second) {
+						candidate, ok := s.at(day, h, m, sec)
+						if ok && candidate.After(t) {
+							return candidate
+						}
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// Prev returns the latest scheduled time at or before t, evaluated in t's
+// location. It returns the zero time if nothing matches within the previous
+// few years.
+func (s *Schedule) Prev(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	hours, minutes, seconds := members(s.hour), members(s.minute), members(s.second)
+	for i := 0; i < maxSearchDays; i++ {
+		if s.dayMatches(day) {
+			for hi := len(hours) - 1; hi >= 0; hi-- {
+				for mi := len(minutes) - 1; mi >= 0; mi-- {
+					for si := len(seconds) - 1; si >= 0; si-- {
+						candidate, ok := s.at(day, hours[hi], minutes[mi], seconds[si])
+						if ok && !candidate.After(t) {
+							return candidate
+						}
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return time.Time{}
+}
+
+// at builds the given wall-clock time on day, reporting false if it does
+// not exist in day's location (e.g. it falls in a DST gap).
+func (s *Schedule) at(day time.Time, hour, minute, second int) (time.Time, bool) {
+	candidate := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, day.Location())
+	return candidate, candidate.Day() == day.Day() && candidate.Hour() == hour && candidate.Minute() == minute
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	if !has(s.month, int(t.Month())) {
+		return false
+	}
+
+	domMatch := has(s.dom, t.Day())
+	dowMatch := has(s.dow, int(t.Weekday()))
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+func members(set uint64) []int {
+	values := make([]int, 0, bits.OnesCount64(set))
+	for set != 0 {
+		v := bits.TrailingZeros64(set)
+		values = append(values, v)
+		set &^= 1 << uint(v)
+	}
+	return values
+}
@@ -0,0 +1,116 @@
+package cron_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/time-resource/cron"
+)
+
+var _ = Describe("Parse", func() {
+	DescribeTable("valid expressions",
+		func(spec string) {
+			schedule, err := cron.Parse(spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedule.String()).To(Equal(spec))
+		},
+		Entry("five fields", "0 10 * * SUN"),
+		Entry("six fields with seconds", "30 0 10 * * 0"),
+		Entry("ranges and steps", "*/15 6-9,17-20 * * mon-fri"),
+		Entry("month names", "0 0 1 jan,jul *"),
+		Entry("sunday as 7", "0 0 * * 7"),
+		Entry("daily macro", "@daily"),
+		Entry("hourly macro", "@hourly"),
+	)
+
+	DescribeTable("invalid expressions",
+		func(spec string, message string) {
+			_, err := cron.Parse(spec)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(message))
+		},
+		Entry("too few fields", "0 10 * *", "expected 5 or 6 fields but got 4"),
+		Entry("too many fields", "0 0 10 * * * 2020", "expected 5 or 6 fields but got 7"),
+		Entry("out of range", "0 24 * * *", "hour 24 out of range [0-23]"),
+		Entry("bad value", "0 x * * *", `invalid value in hour field: "x"`),
+		Entry("bad step", "*/0 * * * *", `invalid step in minute field: "*/0"`),
+		Entry("backwards range", "0 9-5 * * *", `invalid range in hour field: "9-5"`),
+		Entry("unknown macro", "@fortnightly", "unknown cron macro: @fortnightly"),
+	)
+})
+
+var _ = Describe("Schedule", func() {
+	var loc *time.Location
+
+	BeforeEach(func() {
+		var err error
+		loc, err = time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	parse := func(spec string) *cron.Schedule {
+		schedule, err := cron.Parse(spec)
+		Expect(err).NotTo(HaveOccurred())
+		return schedule
+	}
+
+	Describe("Next", func() {
+		It("finds the next weekly firing", func() {
+			// 2018-01-03 is a Wednesday
+			from := time.Date(2018, 1, 3, 12, 0, 0, 0, loc)
+			Expect(parse("0 10 * * SUN").Next(from)).To(Equal(time.Date(2018, 1, 7, 10, 0, 0, 0, loc)))
+		})
+
+		It("is strictly after the given time", func() {
+			from := time.Date(2018, 1, 3, 10, 0, 0, 0, loc)
+			Expect(parse("@hourly").Next(from)).To(Equal(time.Date(2018, 1, 3, 11, 0, 0, 0, loc)))
+		})
+
+		It("honors the seconds field", func() {
+			from := time.Date(2018, 1, 3, 10, 0, 0, 0, loc)
+			Expect(parse("*/20 * * * * *").Next(from)).To(Equal(time.Date(2018, 1, 3, 10, 0, 20, 0, loc)))
+		})
+
+		It("matches either day field when both are restricted", func() {
+			// 2018-01-03 is a Wednesday, 2018-01-05 is a Friday
+			from := time.Date(2018, 1, 3, 12, 0, 0, 0, loc)
+			Expect(parse("0 0 15 * FRI").Next(from)).To(Equal(time.Date(2018, 1, 5, 0, 0, 0, 0, loc)))
+		})
+
+		It("skips times that do not exist on DST transition days", func() {
+			from := time.Date(2018, 3, 10, 12, 0, 0, 0, loc)
+			Expect(parse("30 2 * * *").Next(from)).To(Equal(time.Date(2018, 3, 12, 2, 30, 0, 0, loc)))
+		})
+
+		It("returns the zero time for impossible dates", func() {
+			Expect(parse("0 0 30 2 *").Next(time.Date(2018, 1, 1, 0, 0, 0, 0, loc)).IsZero()).To(BeTrue())
+		})
+	})
+
+	Describe("Prev", func() {
+		It("finds the previous weekly firing", func() {
+			from := time.Date(2018, 1, 3, 12, 0, 0, 0, loc)
+			Expect(parse("0 10 * * SUN").Prev(from)).To(Equal(time.Date(2017, 12, 31, 10, 0, 0, 0, loc)))
+		})
+
+		It("includes the given time", func() {
+			from := time.Date(2018, 1, 3, 10, 0, 0, 0, loc)
+			Expect(parse("@hourly").Prev(from)).To(Equal(from))
+		})
+
+		It("finds the previous monthly firing", func() {
+			from := time.Date(2018, 1, 3, 12, 0, 0, 0, loc)
+			Expect(parse("@monthly").Prev(from)).To(Equal(time.Date(2018, 1, 1, 0, 0, 0, 0, loc)))
+		})
+	})
+
+	Describe("Matches", func() {
+		It("evaluates the time in its own location", func() {
+			schedule := parse("0 10 * * *")
+			Expect(schedule.Matches(time.Date(2018, 1, 3, 10, 0, 0, 0, loc))).To(BeTrue())
+			Expect(schedule.Matches(time.Date(2018, 1, 3, 10, 0, 0, 0, loc).UTC())).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,335 @@
+package holidays
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Holiday is a public holiday on a calendar day. Date is midnight UTC of
+// that day.
+type Holiday struct {
+	Name     string
+	Date     time.Time
+	Observed bool
+}
+
+// Country is a set of national public holiday rules.
+type Country struct {
+	Code string
+	Name string
+
+	rules    []rule
+	observed observance
+}
+
+type rule struct {
+	name string
+	date func(year int) time.Time
+
+	// since is the first year the holiday was observed, if it is recent
+	since int
+}
+
+// observance is how a holiday falling on a weekend is moved to a weekday.
+type observance int
+
+const (
+	// not moved
+	observedNever observance = iota
+	// Saturday moves to Friday, Sunday moves to Monday
+	observedNearestWeekday
+	// moves to the next weekday that isn't already a holiday
+	observedNextWeekday
+)
+
+// Lookup returns the holiday rules for an ISO 3166-1 alpha-2 country code.
+func Lookup(code string) (*Country, error) {
+	country, found := countries[strings.ToUpper(code)]
+	if !found {
+		return nil, fmt.Errorf("unknown holiday country: %s, must be one of: %s", code, strings.Join(Codes(), ", "))
+	}
+	return country, nil
+}
+
+// Codes returns the supported country codes, sorted.
+func Codes() []string {
+	codes := make([]string, 0, len(countries))
+	for code := range countries {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Holidays returns the holidays of the given year in date order, including
+// any weekdays on which weekend holidays are observed.
+func (c *Country) Holidays(year int) []Holiday {
+	var holidays []Holiday
+	for _, r := range c.rules {
+		if year < r.since {
+			continue
+		}
+		holidays = append(holidays, Holiday{Name: r.name, Date: r.date(year)})
+	}
+
+	sortByDate(holidays)
+
+	var observed []Holiday
+	for _, holiday := range holidays {
+		weekday := holiday.Date.Weekday()
+		if weekday != time.Saturday && weekday != time.Sunday {
+			continue
+		}
+
+		var date time.Time
+		switch c.observed {
+		case observedNearestWeekday:
+			if weekday == time.Saturday {
+				date = holiday.Date.AddDate(0, 0, -1)
+			} else {
+				date = holiday.Date.AddDate(0, 0, 1)
+			}
+		case observedNextWeekday:
+			date = holiday.Date
+			for isWeekend(date) || containsDate(holidays, date) || containsDate(observed, date) {
+				date = date.AddDate(0, 0, 1)
+			}
+		default:
+			continue
+		}
+
+		observed = append(observed, Holiday{Name: holiday.Name, Date: date, Observed: true})
+	}
+
+	holidays = append(holidays, observed...)
+	sortByDate(holidays)
+
+	return holidays
+}
+
+// IsHoliday reports whether the calendar day of t, in t's location, is a
+// holiday or a day on which one is observed.
+func (c *Country) IsHoliday(t time.Time) bool {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	// observed days can fall into the neighbouring year, e.g. a Saturday
+	// New Year's Day observed on the Friday before
+	for _, year := range []int{t.Year() - 1, t.Year(), t.Year() + 1} {
+		if containsDate(c.cachedHolidays(year), date) {
+			return true
+		}
+	}
+	return false
+}
+
+// holidaysByYear caches the holidays of each country and year, as IsHoliday
+// is asked about every day of the same few years.
+var holidaysByYear sync.Map
+
+type countryYear struct {
+	code string
+	year int
+}
+
+func (c *Country) cachedHolidays(year int) []Holiday {
+	key := countryYear{code: c.Code, year: year}
+	if cached, found := holidaysByYear.Load(key); found {
+		return cached.([]Holiday)
+	}
+
+	holidays := c.Holidays(year)
+	holidaysByYear.Store(key, holidays)
+
+	return holidays
+}
+
+func sortByDate(holidays []Holiday) {
+	slices.SortStableFunc(holidays, func(a, b Holiday) int { return a.Date.Compare(b.Date) })
+}
+
+func containsDate(holidays []Holiday, date time.Time) bool {
+	for _, holiday := range holidays {
+		if holiday.Date.Equal(date) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
+// Easter returns Easter Sunday of the given year in the Gregorian calendar.
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func fixed(month time.Month, day int) func(int) time.Time {
+	return func(year int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// nth is the nth weekday of the month, or counting back from the end of the
+// month if n is negative.
+func nth(n int, weekday time.Weekday, month time.Month) func(int) time.Time {
+	return func(year int) time.Time {
+		if n > 0 {
+			first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+			offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+			return first.AddDate(0, 0, offset+7*(n-1))
+		}
+
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+		return last.AddDate(0, 0, -offset+7*(n+1))
+	}
+}
+
+// onOrBefore is the last given weekday on or before the given day.
+func onOrBefore(weekday time.Weekday, month time.Month, day int) func(int) time.Time {
+	return func(year int) time.Time {
+		date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		offset := (int(date.Weekday()) - int(weekday) + 7) % 7
+		return date.AddDate(0, 0, -offset)
+	}
+}
+
+func easter(offset int) func(int) time.Time {
+	return func(year int) time.Time {
+		return Easter(year).AddDate(0, 0, offset)
+	}
+}
+
+// sundayTo moves a fixed holiday falling on a Sunday by the given number of
+// days, as some countries do regardless of their general observance.
+func sundayTo(days int, date func(int) time.Time) func(int) time.Time {
+	return func(year int) time.Time {
+		d := date(year)
+		if d.Weekday() == time.Sunday {
+			return d.AddDate(0, 0, days)
+		}
+		return d
+	}
+}
+
+var countries = map[string]*Country{
+	"US": {
+		Code:     "US",
+		Name:     "United States",
+		observed: observedNearestWeekday,
+		rules: []rule{
+			{name: "New Year's Day", date: fixed(time.January, 1)},
+			{name: "Martin Luther King Jr. Day", date: nth(3, time.Monday, time.January)},
+			{name: "Washington's Birthday", date: nth(3, time.Monday, time.February)},
+			{name: "Memorial Day", date: nth(-1, time.Monday, time.May)},
+			{name: "Juneteenth", date: fixed(time.June, 19), since: 2021},
+			{name: "Independence Day", date: fixed(time.July, 4)},
+			{name: "Labor Day", date: nth(1, time.Monday, time.September)},
+			{name: "Columbus Day", date: nth(2, time.Monday, time.October)},
+			{name: "Veterans Day", date: fixed(time.November, 11)},
+			{name: "Thanksgiving Day", date: nth(4, time.Thursday, time.November)},
+			{name: "Christmas Day", date: fixed(time.December, 25)},
+		},
+	},
+	"GB": {
+		Code:     "GB",
+		Name:     "United Kingdom (England and Wales)",
+		observed: observedNextWeekday,
+		rules: []rule{
+			{name: "New Year's Day", date: fixed(time.January, 1)},
+			{name: "Good Friday", date: easter(-2)},
+			{name: "Easter Monday", date: easter(1)},
+			{name: "Early May Bank Holiday", date: nth(1, time.Monday, time.May)},
+			{name: "Spring Bank Holiday", date: nth(-1, time.Monday, time.May)},
+			{name: "Summer Bank Holiday", date: nth(-1, time.Monday, time.August)},
+			{name: "Christmas Day", date: fixed(time.December, 25)},
+			{name: "Boxing Day", date: fixed(time.December, 26)},
+		},
+	},
+	"DE": {
+		Code: "DE",
+		Name: "Germany",
+		rules: []rule{
+			{name: "Neujahr", date: fixed(time.January, 1)},
+			{name: "Karfreitag", date: easter(-2)},
+			{name: "Ostermontag", date: easter(1)},
+			{name: "Tag der Arbeit", date: fixed(time.May, 1)},
+			{name: "Christi Himmelfahrt", date: easter(39)},
+			{name: "Pfingstmontag", date: easter(50)},
+			{name: "Tag der Deutschen Einheit", date: fixed(time.October, 3)},
+			{name: "Erster Weihnachtstag", date: fixed(time.December, 25)},
+			{name: "Zweiter Weihnachtstag", date: fixed(time.December, 26)},
+		},
+	},
+	"FR": {
+		Code: "FR",
+		Name: "France",
+		rules: []rule{
+			{name: "Jour de l'an", date: fixed(time.January, 1)},
+			{name: "Lundi de Pâques", date: easter(1)},
+			{name: "Fête du Travail", date: fixed(time.May, 1)},
+			{name: "Victoire 1945", date: fixed(time.May, 8)},
+			{name: "Ascension", date: easter(39)},
+			{name: "Lundi de Pentecôte", date: easter(50)},
+			{name: "Fête nationale", date: fixed(time.July, 14)},
+			{name: "Assomption", date: fixed(time.August, 15)},
+			{name: "Toussaint", date: fixed(time.November, 1)},
+			{name: "Armistice 1918", date: fixed(time.November, 11)},
+			{name: "Noël", date: fixed(time.December, 25)},
+		},
+	},
+	"NL": {
+		Code: "NL",
+		Name: "Netherlands",
+		rules: []rule{
+			{name: "Nieuwjaarsdag", date: fixed(time.January, 1)},
+			{name: "Eerste Paasdag", date: easter(0)},
+			{name: "Tweede Paasdag", date: easter(1)},
+			{name: "Koningsdag", date: sundayTo(-1, fixed(time.April, 27))},
+			{name: "Bevrijdingsdag", date: fixed(time.May, 5)},
+			{name: "Hemelvaartsdag", date: easter(39)},
+			{name: "Eerste Pinksterdag", date: easter(49)},
+			{name: "Tweede Pinksterdag", date: easter(50)},
+			{name: "Eerste Kerstdag", date: fixed(time.December, 25)},
+			{name: "Tweede Kerstdag", date: fixed(time.December, 26)},
+		},
+	},
+	"CA": {
+		Code:     "CA",
+		Name:     "Canada",
+		observed: observedNextWeekday,
+		rules: []rule{
+			{name: "New Year's Day", date: fixed(time.January, 1)},
+			{name: "Good Friday", date: easter(-2)},
+			{name: "Victoria Day", date: onOrBefore(time.Monday, time.May, 24)},
+			{name: "Canada Day", date: fixed(time.July, 1)},
+			{name: "Labour Day", date: nth(1, time.Monday, time.September)},
+			{name: "National Day for Truth and Reconciliation", date: fixed(time.September, 30), since: 2021},
+			{name: "Thanksgiving", date: nth(2, time.Monday, time.October)},
+			{name: "Remembrance Day", date: fixed(time.November, 11)},
+			{name: "Christmas Day", date: fixed(time.December, 25)},
+			{name: "Boxing Day", date: fixed(time.December, 26)},
+		},
+	},
+}
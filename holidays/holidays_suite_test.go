@@ -0,0 +1,13 @@
+package holidays_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHolidays(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Holidays Suite")
+}
@@ -0,0 +1,99 @@
+package holidays_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/time-resource/holidays"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+var _ = DescribeTable("Easter", func(year int, expected time.Time) {
+	Expect(holidays.Easter(year)).To(Equal(expected))
+},
+	Entry("2018", 2018, date(2018, time.April, 1)),
+	Entry("2019", 2019, date(2019, time.April, 21)),
+	Entry("2024", 2024, date(2024, time.March, 31)),
+	Entry("2025", 2025, date(2025, time.April, 20)),
+	Entry("2026", 2026, date(2026, time.April, 5)),
+	Entry("2038", 2038, date(2038, time.April, 25)),
+)
+
+var _ = Describe("Lookup", func() {
+	It("is case insensitive", func() {
+		country, err := holidays.Lookup("gb")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(country.Code).To(Equal("GB"))
+	})
+
+	It("rejects unknown countries", func() {
+		_, err := holidays.Lookup("XX")
+		Expect(err).To(MatchError("unknown holiday country: XX, must be one of: CA, DE, FR, GB, NL, US"))
+	})
+})
+
+var _ = DescribeTable("IsHoliday", func(code string, day time.Time, expected bool) {
+	country, err := holidays.Lookup(code)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(country.IsHoliday(day)).To(Equal(expected))
+},
+	Entry("a fixed holiday", "DE", date(2026, time.October, 3), true),
+	Entry("a fixed holiday on a weekend that is not moved", "DE", date(2026, time.October, 5), false),
+	Entry("an Easter-relative holiday", "DE", date(2026, time.May, 14), true),
+	Entry("Whit Monday", "FR", date(2026, time.May, 25), true),
+	Entry("a weekday-relative holiday", "US", date(2026, time.November, 26), true),
+	Entry("the last Monday of the month", "US", date(2026, time.May, 25), true),
+	Entry("a holiday not yet introduced", "US", date(2020, time.June, 19), false),
+	Entry("a holiday once introduced", "US", date(2021, time.June, 18), true),
+	Entry("a Saturday holiday observed on the Friday before", "US", date(2026, time.July, 3), true),
+	Entry("a Sunday holiday observed on the Monday after", "US", date(2023, time.January, 2), true),
+	Entry("a Saturday New Year's Day observed in the previous year", "US", date(2021, time.December, 31), true),
+	Entry("a substitute day after a weekend Christmas", "GB", date(2021, time.December, 27), true),
+	Entry("a substitute day after a weekend Boxing Day", "GB", date(2021, time.December, 28), true),
+	Entry("the day after the substitute days", "GB", date(2021, time.December, 29), false),
+	Entry("a Victoria Day", "CA", date(2026, time.May, 18), true),
+	Entry("King's Day moved off a Sunday", "NL", date(2025, time.April, 26), true),
+	Entry("an ordinary day", "GB", date(2026, time.March, 10), false),
+	Entry("a time late in the day", "GB", time.Date(2026, time.December, 25, 23, 59, 0, 0, time.UTC), true),
+)
+
+var _ = Describe("Holidays", func() {
+	It("lists the holidays of a year in order", func() {
+		country, err := holidays.Lookup("GB")
+		Expect(err).NotTo(HaveOccurred())
+
+		var dates []time.Time
+		for _, holiday := range country.Holidays(2022) {
+			dates = append(dates, holiday.Date)
+		}
+
+		Expect(dates).To(Equal([]time.Time{
+			date(2022, time.January, 1),
+			date(2022, time.January, 3),
+			date(2022, time.April, 15),
+			date(2022, time.April, 18),
+			date(2022, time.May, 2),
+			date(2022, time.May, 30),
+			date(2022, time.August, 29),
+			date(2022, time.December, 25),
+			date(2022, time.December, 26),
+			date(2022, time.December, 27),
+		}))
+	})
+
+	It("marks observed days", func() {
+		country, err := holidays.Lookup("US")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(country.Holidays(2026)).To(ContainElement(holidays.Holiday{
+			Name:     "Independence Day",
+			Date:     date(2026, time.July, 3),
+			Observed: true,
+		}))
+	})
+})
@@ -0,0 +1,289 @@
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/concourse/time-resource/rrule"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405"
+	utcFormat      = "20060102T150405Z"
+)
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// Calendar is the set of events parsed from an iCalendar file.
+type Calendar struct {
+	Events []Event
+}
+
+// Event is a VEVENT. Start and End delimit its first occurrence; End is
+// exclusive. All-day events start at midnight in the calendar's default
+// location.
+type Event struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+	AllDay  bool
+	RRule   *rrule.Rule
+	ExDates []time.Time
+}
+
+// ParseFile parses the iCalendar file at path. See Parse.
+func ParseFile(path string, defaultLoc *time.Location) (*Calendar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file, defaultLoc)
+}
+
+// Parse reads the VEVENTs of an iCalendar stream. Floating times and
+// all-day dates are interpreted in defaultLoc; times with a TZID are
+// interpreted in that zone.
+func Parse(r io.Reader, defaultLoc *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &Calendar{}
+
+	var event *Event
+	var hasEnd bool
+	var duration string
+	for i, line := range lines {
+		name, params, value, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &Event{}
+			hasEnd = false
+			duration = ""
+
+		case name == "END" && value == "VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", i+1)
+			}
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: VEVENT without DTSTART", i+1)
+			}
+
+			if !hasEnd {
+				event.End, err = eventEnd(*event, duration)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", i+1, err)
+				}
+			}
+
+			calendar.Events = append(calendar.Events, *event)
+			event = nil
+
+		case event == nil:
+			// properties outside of a VEVENT (VCALENDAR, VTIMEZONE, ...)
+
+		case name == "SUMMARY":
+			event.Summary = unescape(value)
+
+		case name == "DTSTART":
+			event.Start, event.AllDay, err = parseTime(value, params, defaultLoc)
+
+		case name == "DTEND":
+			event.End, _, err = parseTime(value, params, defaultLoc)
+			hasEnd = true
+
+		case name == "DURATION":
+			duration = value
+
+		case name == "RRULE":
+			event.RRule, err = rrule.Parse(value)
+
+		case name == "EXDATE":
+			for _, exdate := range strings.Split(value, ",") {
+				var t time.Time
+				t, _, err = parseTime(exdate, params, defaultLoc)
+				if err != nil {
+					break
+				}
+				event.ExDates = append(event.ExDates, t)
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", i+1, name, err)
+		}
+	}
+
+	if event != nil {
+		return nil, fmt.Errorf("unterminated VEVENT")
+	}
+
+	return calendar, nil
+}
+
+// Contains reports whether t falls within an occurrence of any event.
+func (c *Calendar) Contains(t time.Time) bool {
+	for _, event := range c.Events {
+		if event.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether t falls within an occurrence of the event that
+// has not been excluded by EXDATE.
+func (e Event) Contains(t time.Time) bool {
+	if e.RRule == nil {
+		return !t.Before(e.Start) && t.Before(e.End)
+	}
+
+	recurrence := e.RRule.Recurrence(e.Start)
+	for occurrence := recurrence.Prev(t); !occurrence.IsZero(); occurrence = recurrence.Prev(occurrence.Add(-time.Nanosecond)) {
+		if !t.Before(e.occurrenceEnd(occurrence)) {
+			return false
+		}
+		if !e.excluded(occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Event) occurrenceEnd(occurrence time.Time) time.Time {
+	if e.AllDay {
+		days := int(e.End.Sub(e.Start).Round(24*time.Hour) / (24 * time.Hour))
+		return occurrence.AddDate(0, 0, days)
+	}
+	return occurrence.Add(e.End.Sub(e.Start))
+}
+
+func (e Event) excluded(occurrence time.Time) bool {
+	for _, exdate := range e.ExDates {
+		if exdate.Equal(occurrence) {
+			return true
+		}
+		if e.AllDay && exdate.Year() == occurrence.Year() && exdate.YearDay() == occurrence.YearDay() {
+			return true
+		}
+	}
+	return false
+}
+
+func eventEnd(event Event, duration string) (time.Time, error) {
+	if duration == "" {
+		if event.AllDay {
+			return event.Start.AddDate(0, 0, 1), nil
+		}
+		return event.Start, nil
+	}
+
+	match := durationPattern.FindStringSubmatch(duration)
+	if match == nil || match[1] == "-" {
+		return time.Time{}, fmt.Errorf("invalid DURATION: %s", duration)
+	}
+
+	part := func(idx int) int {
+		v, _ := strconv.Atoi(match[idx])
+		return v
+	}
+
+	end := event.Start.AddDate(0, 0, 7*part(2)+part(3))
+	end = end.Add(time.Duration(part(4))*time.Hour +
+		time.Duration(part(5))*time.Minute +
+		time.Duration(part(6))*time.Second)
+
+	return end, nil
+}
+
+func parseTime(value string, params map[string]string, defaultLoc *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateFormat) {
+		t, err := time.ParseInLocation(dateFormat, value, defaultLoc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcFormat, value)
+		return t, false, err
+	}
+
+	loc := defaultLoc
+	if tzid, found := params["TZID"]; found {
+		var err error
+		loc, err = time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown TZID: %s", tzid)
+		}
+	}
+
+	t, err := time.ParseInLocation(dateTimeFormat, value, loc)
+	return t, false, err
+}
+
+// unfold joins content lines that were folded onto several physical lines.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseLine splits a content line into its upper-cased name, parameters and
+// value, e.g. "DTSTART;TZID=Europe/Berlin:20260101T090000".
+func parseLine(line string) (string, map[string]string, string, error) {
+	params := map[string]string{}
+
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", fmt.Errorf("invalid content line: %q", line)
+	}
+
+	nameAndParams := strings.Split(line[:colon], ";")
+	for _, param := range nameAndParams[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+
+	return strings.ToUpper(nameAndParams[0]), params, line[colon+1:], nil
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,13 @@
+package ics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestICS(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ICS Suite")
+}
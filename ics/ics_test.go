@@ -0,0 +1,129 @@
+package ics_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/time-resource/ics"
+)
+
+const calendarFile = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//Holidays//EN
+BEGIN:VTIMEZONE
+TZID:Europe/Berlin
+END:VTIMEZONE
+BEGIN:VEVENT
+SUMMARY:New Year's Day
+DTSTART;VALUE=DATE:20260101
+DTEND;VALUE=DATE:20260102
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Change freeze\, end of year
+DTSTART;VALUE=DATE:20261221
+DTEND;VALUE=DATE:20261224
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Weekly backup
+DTSTART;TZID=Europe/Berlin:20260105T020000
+DURATION:PT2H
+RRULE:FREQ=WEEKLY;BYDAY=MO
+EXDATE;TZID=Europe/Berlin:20260112T020000
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Maintenance
+DTSTART:20260303T230000Z
+DTEND:20260304T010000Z
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Monthly release
+  day
+DTSTART;VALUE=DATE:20260115
+RRULE:FREQ=MONTHLY;BYMONTHDAY=15
+END:VEVENT
+END:VCALENDAR
+`
+
+var _ = Describe("Parse", func() {
+	var (
+		calendar *ics.Calendar
+		berlin   *time.Location
+		newYork  *time.Location
+	)
+
+	BeforeEach(func() {
+		var err error
+		berlin, err = time.LoadLocation("Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+
+		newYork, err = time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+
+		calendar, err = ics.Parse(strings.NewReader(strings.ReplaceAll(calendarFile, "\n", "\r\n")), newYork)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("parses every event", func() {
+		Expect(calendar.Events).To(HaveLen(5))
+		Expect(calendar.Events[1].Summary).To(Equal("Change freeze, end of year"))
+		Expect(calendar.Events[4].Summary).To(Equal("Monthly release day"))
+	})
+
+	It("interprets all-day events in the default location", func() {
+		event := calendar.Events[0]
+		Expect(event.AllDay).To(BeTrue())
+		Expect(event.Start).To(Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, newYork)))
+		Expect(event.End).To(Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, newYork)))
+
+		Expect(calendar.Contains(time.Date(2026, 1, 1, 23, 0, 0, 0, newYork))).To(BeTrue())
+		Expect(calendar.Contains(time.Date(2026, 1, 2, 0, 0, 0, 0, newYork))).To(BeFalse())
+	})
+
+	It("treats DTEND as exclusive for multi-day events", func() {
+		Expect(calendar.Contains(time.Date(2026, 12, 23, 12, 0, 0, 0, newYork))).To(BeTrue())
+		Expect(calendar.Contains(time.Date(2026, 12, 24, 12, 0, 0, 0, newYork))).To(BeFalse())
+	})
+
+	It("interprets times with a TZID in that zone", func() {
+		Expect(calendar.Events[2].Start).To(Equal(time.Date(2026, 1, 5, 2, 0, 0, 0, berlin)))
+		Expect(calendar.Events[2].End).To(Equal(time.Date(2026, 1, 5, 4, 0, 0, 0, berlin)))
+	})
+
+	It("interprets UTC times", func() {
+		Expect(calendar.Contains(time.Date(2026, 3, 4, 0, 30, 0, 0, time.UTC))).To(BeTrue())
+		Expect(calendar.Contains(time.Date(2026, 3, 4, 1, 0, 0, 0, time.UTC))).To(BeFalse())
+	})
+
+	It("expands recurring events", func() {
+		Expect(calendar.Contains(time.Date(2026, 2, 2, 3, 0, 0, 0, berlin))).To(BeTrue())
+		Expect(calendar.Contains(time.Date(2026, 2, 2, 4, 0, 0, 0, berlin))).To(BeFalse())
+		Expect(calendar.Contains(time.Date(2026, 2, 3, 3, 0, 0, 0, berlin))).To(BeFalse())
+	})
+
+	It("skips occurrences excluded by EXDATE", func() {
+		Expect(calendar.Contains(time.Date(2026, 1, 12, 3, 0, 0, 0, berlin))).To(BeFalse())
+		Expect(calendar.Contains(time.Date(2026, 1, 19, 3, 0, 0, 0, berlin))).To(BeTrue())
+	})
+
+	It("expands recurring all-day events", func() {
+		Expect(calendar.Contains(time.Date(2026, 6, 15, 18, 0, 0, 0, newYork))).To(BeTrue())
+		Expect(calendar.Contains(time.Date(2026, 6, 16, 0, 0, 0, 0, newYork))).To(BeFalse())
+	})
+
+	DescribeTable("invalid calendars",
+		func(content string, message string) {
+			_, err := ics.Parse(strings.NewReader(content), time.UTC)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(message))
+		},
+		Entry("missing DTSTART", "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n", "VEVENT without DTSTART"),
+		Entry("unterminated event", "BEGIN:VEVENT\nDTSTART:20260101T000000Z\n", "unterminated VEVENT"),
+		Entry("unknown TZID", "BEGIN:VEVENT\nDTSTART;TZID=Mars/Olympus:20260101T000000\nEND:VEVENT\n", "unknown TZID: Mars/Olympus"),
+		Entry("bad RRULE", "BEGIN:VEVENT\nDTSTART:20260101T000000Z\nRRULE:FREQ=SOMETIMES\nEND:VEVENT\n", "unsupported rrule FREQ"),
+		Entry("bad DURATION", "BEGIN:VEVENT\nDTSTART:20260101T000000Z\nDURATION:2 hours\nEND:VEVENT\n", "invalid DURATION"),
+		Entry("bad content line", "BEGIN:VEVENT\nnonsense\nEND:VEVENT\n", "invalid content line"),
+	)
+})
@@ -25,7 +25,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	command := resource.InCommand{}
+	clock, err := resource.ClockFromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error:", err.Error())
+		os.Exit(1)
+	}
+
+	command := resource.InCommand{Clock: clock}
 
 	response, err := command.Run(destination, request)
 	if err != nil {
@@ -6,15 +6,16 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"time"
 
 	"github.com/concourse/time-resource/models"
 )
 
 type InCommand struct {
+	// Clock tells the current time, defaulting to the system's clock
+	Clock Clock
 }
 
-func (*InCommand) Run(destination string, request models.InRequest) (models.InResponse, error) {
+func (command *InCommand) Run(destination string, request models.InRequest) (models.InResponse, error) {
 	err := os.MkdirAll(destination, 0755)
 	if err != nil {
 		return models.InResponse{}, fmt.Errorf("creating destination: %w", err)
@@ -33,7 +34,7 @@ func (*InCommand) Run(destination string, request models.InRequest) (models.InRe
 
 	versionTime := request.Version.Time
 	if versionTime.IsZero() {
-		versionTime = time.Now()
+		versionTime = currentTime(command.Clock)
 	}
 
 	timeFile, err := os.Create(filepath.Join(destination, "timestamp"))
@@ -57,8 +58,10 @@ func (*InCommand) Run(destination string, request models.InRequest) (models.InRe
 		return models.InResponse{}, fmt.Errorf("writing epoch file: %w", err)
 	}
 
+	metadata := versionMetadata(request.Source, versionTime, "")
+
 	inVersion := models.Version{Time: versionTime}
-	response := models.InResponse{Version: inVersion}
+	response := models.InResponse{Version: inVersion, Metadata: metadata}
 
 	return response, nil
 }
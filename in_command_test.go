@@ -22,6 +22,7 @@ var _ = Describe("In", func() {
 		source   models.Source
 		version  models.Version
 		response models.InResponse
+		clock    resource.Clock
 
 		err error
 	)
@@ -38,10 +39,11 @@ var _ = Describe("In", func() {
 		source = models.Source{Interval: &interval}
 
 		response = models.InResponse{}
+		clock = nil
 	})
 
 	JustBeforeEach(func() {
-		command := resource.InCommand{}
+		command := resource.InCommand{Clock: clock}
 		response, err = command.Run(destination, models.InRequest{
 			Source:  source,
 			Version: version,
@@ -89,6 +91,126 @@ var _ = Describe("In", func() {
 			Expect(givenTime.Unix()).To(Equal(int64(epochi)))
 		})
 
+		Context("when the version's time is shortly after the start of the source's window", func() {
+			BeforeEach(func() {
+				loc, err := time.LoadLocation("Europe/Berlin")
+				Expect(err).NotTo(HaveOccurred())
+
+				start := models.NewTimeOfDay(time.Date(0, 1, 1, 9, 0, 0, 0, loc))
+				stop := models.NewTimeOfDay(time.Date(0, 1, 1, 10, 0, 0, 0, loc))
+				location := models.Location(*loc)
+				source = models.Source{Start: &start, Stop: &stop, Location: &location}
+
+				version = models.Version{Time: time.Date(2018, 1, 8, 8, 0, 20, 0, time.UTC)}
+			})
+
+			It("reports the time and window as scheduled in the metadata", func() {
+				Expect(response.Metadata).To(Equal(models.Metadata{
+					{Name: "time", Value: "2018-01-08 09:00:20 CET"},
+					{Name: "utc", Value: "2018-01-08T08:00:20Z"},
+					{Name: "epoch", Value: "1515398420"},
+					{Name: "weekday", Value: "Monday"},
+					{Name: "iso_week", Value: "2018-W02"},
+					{Name: "trigger", Value: "scheduled"},
+					{Name: "window", Value: "2018-01-08 09:00:00 CET to 2018-01-08 10:00:00 CET"},
+				}))
+			})
+
+			Context("when the version's time is later within the window", func() {
+				BeforeEach(func() {
+					version = models.Version{Time: time.Date(2018, 1, 8, 8, 30, 0, 0, time.UTC)}
+				})
+
+				It("reports the time as manual within the window in the metadata", func() {
+					Expect(response.Metadata).To(ContainElement(models.MetadataField{Name: "trigger", Value: "manual"}))
+					Expect(response.Metadata).To(ContainElement(models.MetadataField{Name: "window", Value: "2018-01-08 09:00:00 CET to 2018-01-08 10:00:00 CET"}))
+				})
+			})
+
+			Context("when the version's time is outside the window", func() {
+				BeforeEach(func() {
+					version = models.Version{Time: time.Date(2018, 1, 8, 12, 0, 0, 0, time.UTC)}
+				})
+
+				It("reports the time as manual without a window in the metadata", func() {
+					Expect(response.Metadata).To(ContainElement(models.MetadataField{Name: "time", Value: "2018-01-08 13:00:00 CET"}))
+					Expect(response.Metadata).To(ContainElement(models.MetadataField{Name: "trigger", Value: "manual"}))
+					Expect(response.Metadata).NotTo(ContainElement(HaveField("Name", "window")))
+				})
+			})
+		})
+
+		Context("when the source has an interval", func() {
+			BeforeEach(func() {
+				interval := models.Interval(time.Hour)
+				source = models.Source{Interval: &interval}
+			})
+
+			Context("when the version's time is between the interval's times", func() {
+				BeforeEach(func() {
+					version = models.Version{Time: time.Date(2018, 1, 8, 11, 17, 30, 0, time.UTC)}
+				})
+
+				It("reports the time as scheduled in the metadata", func() {
+					Expect(response.Metadata).To(ContainElement(models.MetadataField{Name: "trigger", Value: "scheduled"}))
+				})
+			})
+
+			Context("when the version's time is outside the source's window", func() {
+				BeforeEach(func() {
+					start := models.NewTimeOfDay(time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC))
+					stop := models.NewTimeOfDay(time.Date(0, 1, 1, 17, 0, 0, 0, time.UTC))
+					source.Start = &start
+					source.Stop = &stop
+
+					version = models.Version{Time: time.Date(2018, 1, 8, 18, 17, 30, 0, time.UTC)}
+				})
+
+				It("reports the time as manual in the metadata", func() {
+					Expect(response.Metadata).To(ContainElement(models.MetadataField{Name: "trigger", Value: "manual"}))
+				})
+			})
+		})
+
+		Context("when the source has an aligned interval", func() {
+			BeforeEach(func() {
+				interval := models.Interval(time.Hour)
+				source = models.Source{Interval: &interval, Aligned: true}
+			})
+
+			Context("when the version's time is one of the interval's times", func() {
+				BeforeEach(func() {
+					version = models.Version{Time: time.Date(2018, 1, 8, 10, 0, 0, 0, time.UTC)}
+				})
+
+				It("reports the time as scheduled in the metadata", func() {
+					Expect(response.Metadata).To(ContainElement(models.MetadataField{Name: "trigger", Value: "scheduled"}))
+				})
+			})
+
+			Context("when the version's time is between the interval's times", func() {
+				BeforeEach(func() {
+					version = models.Version{Time: time.Date(2018, 1, 8, 10, 25, 0, 0, time.UTC)}
+				})
+
+				It("reports the time as manual in the metadata", func() {
+					Expect(response.Metadata).To(ContainElement(models.MetadataField{Name: "trigger", Value: "manual"}))
+				})
+			})
+		})
+
+		Context("when the source's calendars cannot be loaded", func() {
+			BeforeEach(func() {
+				source.BlackoutCalendars = []string{filepath.Join(tmpdir, "missing.ics")}
+			})
+
+			It("reports the time without the schedule in the metadata", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(response.Metadata).To(HaveLen(5))
+				Expect(response.Metadata).NotTo(ContainElement(HaveField("Name", "trigger")))
+			})
+		})
+
 		Context("when the request has no time in its version", func() {
 			BeforeEach(func() {
 				version = models.Version{}
@@ -97,6 +219,16 @@ var _ = Describe("In", func() {
 			It("reports the current time as the version", func() {
 				Expect(response.Version.Time.Unix()).To(BeNumerically("~", time.Now().Unix(), 1))
 			})
+
+			Context("when a clock is given", func() {
+				BeforeEach(func() {
+					clock = resource.FixedClock(time.Date(2018, 1, 8, 10, 30, 0, 0, time.UTC))
+				})
+
+				It("reports the clock's time as the version", func() {
+					Expect(response.Version.Time).To(Equal(time.Date(2018, 1, 8, 10, 30, 0, 0, time.UTC)))
+				})
+			})
 		})
 	})
 })
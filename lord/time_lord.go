@@ -1,157 +1,469 @@
 package lord
 
 import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
 	"time"
 
+	"github.com/concourse/time-resource/cron"
+	"github.com/concourse/time-resource/holidays"
+	"github.com/concourse/time-resource/ics"
 	"github.com/concourse/time-resource/models"
+	"github.com/concourse/time-resource/rrule"
+	"github.com/concourse/time-resource/solar"
 )
 
-var DEFAULT_TIME_OF_DAY = models.TimeOfDay(time.Duration(0))
+var DEFAULT_TIME_OF_DAY = models.NewTimeOfDay(time.Time{})
+
+// MAX_DAYS_SEARCHED bounds how far back Latest and RangesBefore will look
+// for a matching day, so that day restrictions which can never match
+// terminate.
+const MAX_DAYS_SEARCHED = 366 * 8
 
 type TimeLord struct {
-	PreviousTime time.Time
-	Location     *models.Location
-	Start        *models.TimeOfDay
-	Stop         *models.TimeOfDay
-	Interval     *models.Interval
-	Days         []models.Weekday
-	StartAfter   *models.StartAfter
+	PreviousTime   time.Time
+	Location       *models.Location
+	Latitude       float64
+	Longitude      float64
+	Start          *models.TimeOfDay
+	Stop           *models.TimeOfDay
+	Interval       *models.Interval
+	IntervalAnchor models.IntervalAnchor
+	Windows        []models.Window
+	Schedule       models.Schedule
+	Blackouts      []models.Blackout
+	Days           []models.Weekday
+	DaysOfMonth    []int
+	BusinessDays   []int
+	Months         []models.Month
+	Weeks          []int
+	WeekParity     string
+	ExcludeDates   []models.DateRange
+	SkipHolidays   []models.HolidayCountry
+	StartAfter     *models.StartAfter
+	StopAfter      *models.StopAfter
+	EveryNDays     int
+	EveryNWeeks    int
+	Anchor         *models.Anchor
+	DSTPolicy      models.DSTPolicy
+	Cron           *models.Cron
+	RRule          *models.RRule
+
+	BlackoutCalendars []*ics.Calendar
+	AllowCalendars    []*ics.Calendar
 }
 
-func (tl TimeLord) Check(now time.Time) bool {
+// occurrences is a schedule of discrete instants, as described by a cron
+// expression or a recurrence rule.
+type occurrences interface {
+	Next(time.Time) time.Time
+	Prev(time.Time) time.Time
+}
 
-	start, stop := tl.LatestRangeBefore(now)
+// Decision is the outcome of Check and the reason for it.
+type Decision struct {
+	Fire   bool
+	Reason Reason
 
-	if !tl.daysMatch(now) {
-		return false
+	// Range is the range containing the time checked, if there is one
+	Range Range
+}
+
+// Reason explains a Decision.
+type Reason string
+
+const (
+	NO_PREVIOUS_VERSION  Reason = "no previous version"
+	INTERVAL_ELAPSED     Reason = "interval elapsed since previous version"
+	WINDOW_STARTED       Reason = "window started since previous version"
+	SCHEDULED_TIME       Reason = "scheduled time since previous version"
+	DAY_NOT_MATCHED      Reason = "day not matched"
+	NOT_ALLOWED          Reason = "blacked out or not allowed by calendars"
+	BEFORE_START_AFTER   Reason = "before start_after"
+	AFTER_STOP_AFTER     Reason = "after stop_after"
+	OUTSIDE_WINDOW       Reason = "outside window"
+	NO_SCHEDULED_TIME    Reason = "no scheduled time yet"
+	INTERVAL_NOT_ELAPSED Reason = "interval not elapsed since previous version"
+	ALREADY_FIRED        Reason = "already fired this window"
+)
+
+func (d Decision) String() string {
+	outcome := "skip"
+	if d.Fire {
+		outcome = "fire"
 	}
 
-	if tl.StartAfter != nil {
-		startAfter := time.Time(*tl.StartAfter)
-		startInLoc := time.Date(startAfter.Year(), startAfter.Month(), startAfter.Day(),
-			startAfter.Hour(), startAfter.Minute(), startAfter.Second(), 0, tl.loc())
+	if d.Range.Start.IsZero() {
+		return fmt.Sprintf("%s: %s", outcome, d.Reason)
+	}
 
-		if !startInLoc.Before(now) {
-			return false
+	return fmt.Sprintf("%s: %s (window %s to %s)", outcome, d.Reason,
+		d.Range.Start.Format(time.RFC3339), d.Range.Stop.Format(time.RFC3339))
+}
+
+func (tl TimeLord) Check(now time.Time) Decision {
+	// cron and rrule occurrences are matched against days and calendars
+	// themselves, as they can be checked after their day has passed
+	if tl.occurrences() == nil {
+		if !tl.daysMatch(now) {
+			return Decision{Reason: DAY_NOT_MATCHED}
+		}
+
+		if !tl.allowed(now) {
+			return Decision{Reason: NOT_ALLOWED}
 		}
 	}
 
-	if !start.IsZero() && (now.Before(start) || !now.Before(stop)) {
-		return false
+	if tl.StartAfter != nil && !tl.startAfterInLoc().Before(now) {
+		return Decision{Reason: BEFORE_START_AFTER}
 	}
 
-	if tl.PreviousTime.IsZero() {
-		return true
+	if tl.StopAfter != nil && now.After(tl.stopAfterInLoc()) {
+		return Decision{Reason: AFTER_STOP_AFTER}
 	}
 
-	if tl.Interval != nil {
-		if now.Sub(tl.PreviousTime) >= time.Duration(*tl.Interval) {
-			return true
+	if tl.occurrences() != nil {
+		fired := tl.latestOccurrenceBefore(now)
+		switch {
+		case fired.IsZero():
+			return Decision{Reason: NO_SCHEDULED_TIME}
+		case tl.PreviousTime.IsZero():
+			return Decision{Fire: true, Reason: NO_PREVIOUS_VERSION}
+		case tl.PreviousTime.Before(fired):
+			return Decision{Fire: true, Reason: SCHEDULED_TIME}
 		}
-	} else if !start.IsZero() {
-		return tl.PreviousTime.Before(start)
+		return Decision{Reason: ALREADY_FIRED}
 	}
 
-	return false
+	decision := Decision{Reason: OUTSIDE_WINDOW}
+
+	// a range lasts at most a day, so only ranges starting within the last
+	// two days can contain now
+	earliest := now.AddDate(0, 0, -2)
+
+	for r := range tl.RangesBefore(now) {
+		if r.Start.Before(earliest) {
+			break
+		}
+
+		if !now.Before(r.Stop) {
+			continue
+		}
+
+		if tl.PreviousTime.IsZero() {
+			return Decision{Fire: true, Reason: NO_PREVIOUS_VERSION, Range: r}
+		}
+
+		if r.Interval != nil && tl.onWallClock() {
+			decision = Decision{Reason: INTERVAL_NOT_ELAPSED, Range: r}
+			for intervalTime := range tl.intervalTimes(r, r.Start) {
+				if intervalTime.After(now) {
+					break
+				}
+				if intervalTime.After(tl.PreviousTime) {
+					return Decision{Fire: true, Reason: INTERVAL_ELAPSED, Range: r}
+				}
+			}
+		} else if r.Interval != nil {
+			decision = Decision{Reason: INTERVAL_NOT_ELAPSED, Range: r}
+			if now.Sub(tl.PreviousTime) >= time.Duration(*r.Interval) {
+				return Decision{Fire: true, Reason: INTERVAL_ELAPSED, Range: r}
+			}
+		} else {
+			decision = Decision{Reason: ALREADY_FIRED, Range: r}
+			if tl.PreviousTime.Before(r.Start) {
+				return Decision{Fire: true, Reason: WINDOW_STARTED, Range: r}
+			}
+		}
+	}
+
+	return decision
 }
 
 func (tl TimeLord) Latest(reference time.Time) time.Time {
+	reference = tl.untilStopAfter(reference)
+
 	if tl.PreviousTime.After(reference) {
 		return time.Time{}
 	}
 
+	if tl.occurrences() != nil {
+		return tl.latestOccurrenceBefore(reference)
+	}
+
 	refInLoc := reference.In(tl.loc())
-	for !tl.daysMatch(refInLoc) {
+	for searched := 0; !tl.daysMatch(refInLoc); searched++ {
+		if searched > MAX_DAYS_SEARCHED {
+			return time.Time{}
+		}
 		refInLoc = refInLoc.AddDate(0, 0, -1)
 	}
 
-	start, stop := tl.LatestRangeBefore(refInLoc)
+	latest, found := tl.latestRange(refInLoc)
+	if !found {
+		return time.Time{}
+	}
 
-	if tl.PreviousTime.IsZero() && !reference.Before(stop) {
+	if tl.PreviousTime.IsZero() && !reference.Before(latest.Stop) {
 		return time.Time{}
 	}
 
-	if tl.Interval == nil {
-		if tl.PreviousTime.After(start) {
+	if latest.Interval == nil {
+		if tl.PreviousTime.After(latest.Start) {
 			return time.Time{}
 		}
-		return start
-	}
 
-	tlDuration := time.Duration(*tl.Interval)
+		first, found := tl.firstAllowed(latest.Start, latest.Stop)
+		if !found || first.After(reference) {
+			return time.Time{}
+		}
+		return first
+	}
 
 	var latestValidTime time.Time
-	for intervalTime := start; !intervalTime.After(reference) && intervalTime.Before(stop); intervalTime = intervalTime.Add(tlDuration) {
-		latestValidTime = intervalTime
+	for intervalTime := range tl.intervalTimes(latest, latest.Start) {
+		if intervalTime.After(reference) {
+			break
+		}
+		if tl.allowed(intervalTime) {
+			latestValidTime = intervalTime
+		}
 	}
 	return latestValidTime
 }
 
 func (tl TimeLord) List(reference time.Time) []time.Time {
+	reference = tl.untilStopAfter(reference)
+
 	start := tl.PreviousTime
 
-	var addForRange func(time.Time, time.Time)
 	versions := []time.Time{}
 
-	if tl.Interval == nil {
-
+	if schedule := tl.occurrences(); schedule != nil {
 		if start.IsZero() {
-			refRangeStart, refRangeEnd := tl.LatestRangeBefore(reference)
-			if !reference.Before(refRangeEnd) {
-				return versions
+			if latest := tl.latestOccurrenceBefore(reference); !latest.IsZero() {
+				versions = append(versions, latest)
 			}
-			start = refRangeStart
+			return versions
 		}
 
-		addForRange = func(dailyStart, _ time.Time) {
-			if !dailyStart.Before(start) && !dailyStart.After(reference) {
-				versions = append(versions, dailyStart)
+		fired := schedule.Prev(start.In(tl.loc()))
+		if fired.IsZero() {
+			fired = schedule.Next(start.In(tl.loc()))
+		}
+		for ; !fired.IsZero() && !fired.After(reference); fired = schedule.Next(fired) {
+			if !fired.Before(start) && tl.occurrenceMatches(fired) {
+				versions = append(versions, fired)
 			}
 		}
+		return versions
+	}
 
-	} else {
-		tlDuration := time.Duration(*tl.Interval)
-
-		if start.IsZero() {
-			start = reference
+	if start.IsZero() {
+		latest, found := tl.latestRange(reference)
+		if !found || !reference.Before(latest.Stop) {
+			return versions
 		}
 
-		addForRange = func(dailyStart, dailyEnd time.Time) {
-			intervalTime := dailyStart.Truncate(tlDuration)
+		start = reference
+		if latest.Interval == nil {
+			start = latest.Start
+		}
+	}
 
-			for intervalTime.Before(dailyStart) || intervalTime.Before(start) {
-				intervalTime = intervalTime.Add(tlDuration)
+	addForRange := func(r Range) {
+		if r.Interval == nil {
+			if r.Start.Before(start) {
+				return
 			}
+			if first, found := tl.firstAllowed(r.Start, r.Stop); found && !first.After(reference) {
+				versions = append(versions, first)
+			}
+			return
+		}
 
-			for !intervalTime.After(reference) && intervalTime.Before(dailyEnd) {
+		for intervalTime := range tl.intervalTimes(r, r.Start) {
+			if intervalTime.After(reference) {
+				break
+			}
+			if !intervalTime.Before(start) {
 				versions = append(versions, intervalTime)
-				intervalTime = intervalTime.Add(tlDuration)
 			}
 		}
 	}
 
-	var dailyStart, dailyEnd time.Time
-	for dailyInterval := start; !dailyStart.After(reference); dailyInterval = dailyInterval.AddDate(0, 0, 1) {
-		if tl.daysMatch(dailyInterval) {
-			dailyStart, dailyEnd = tl.LatestRangeBefore(dailyInterval)
-			if dailyStart.After(reference) {
-				break
+	// a range lasts at most a day, so start from the day before
+	startInLoc := start.In(tl.loc())
+	firstDay := time.Date(startInLoc.Year(), startInLoc.Month(), startInLoc.Day()-1, 0, 0, 0, 0, tl.loc())
+days:
+	for day := firstDay; !day.After(reference); day = day.AddDate(0, 0, 1) {
+		if !tl.daysMatch(day) {
+			continue
+		}
+
+		for _, r := range tl.rangesOn(day) {
+			if r.Start.After(reference) {
+				break days
 			}
-			addForRange(dailyStart, dailyEnd)
+			addForRange(r)
 		}
 	}
+
+	// overlapping windows can schedule the same time more than once
+	slices.SortFunc(versions, time.Time.Compare)
+	versions = slices.CompactFunc(versions, time.Time.Equal)
+
+	versions = slices.DeleteFunc(versions, func(version time.Time) bool {
+		return (tl.StartAfter != nil && version.Before(tl.startAfterInLoc())) || !tl.allowed(version)
+	})
+
 	return versions
 }
 
 func (tl TimeLord) daysMatch(now time.Time) bool {
-	if len(tl.Days) == 0 {
+	nowInLoc := now.In(tl.loc())
+
+	return tl.weekdayMatches(tl.Days, nowInLoc) &&
+		tl.dayOfMonthMatches(nowInLoc) &&
+		tl.businessDayMatches(nowInLoc) &&
+		tl.monthMatches(nowInLoc) &&
+		tl.weekMatches(nowInLoc) &&
+		!tl.dateExcluded(nowInLoc) &&
+		!tl.holiday(nowInLoc) &&
+		tl.cadenceMatches(nowInLoc)
+}
+
+// cadenceMatches reports whether the day of nowInLoc is on the EveryNDays or
+// EveryNWeeks cadence, counted in calendar days from the anchor, or from
+// StartAfter if there is no anchor. Weeks start on Monday.
+func (tl TimeLord) cadenceMatches(nowInLoc time.Time) bool {
+	if tl.EveryNDays <= 1 && tl.EveryNWeeks <= 1 {
 		return true
 	}
 
-	todayInLoc := models.Weekday(now.In(tl.loc()).Weekday())
+	var anchor time.Time
+	switch {
+	case tl.Anchor != nil:
+		anchor = time.Time(*tl.Anchor)
+	case tl.StartAfter != nil:
+		anchor = tl.startAfterInLoc()
+	default:
+		return true
+	}
+
+	anchorDay := time.Date(anchor.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, time.UTC)
+	nowDay := time.Date(nowInLoc.Year(), nowInLoc.Month(), nowInLoc.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(nowDay.Sub(anchorDay) / (24 * time.Hour))
+
+	if tl.EveryNWeeks > 1 {
+		daysSinceMonday := (int(anchorDay.Weekday()) + 6) % 7
+		weeks := floorDiv(days+daysSinceMonday, 7)
+		return floorMod(weeks, tl.EveryNWeeks) == 0
+	}
+
+	return floorMod(days, tl.EveryNDays) == 0
+}
+
+func floorDiv(a, b int) int {
+	return (a - floorMod(a, b)) / b
+}
 
-	for _, day := range tl.Days {
-		if day == todayInLoc {
+func floorMod(a, b int) int {
+	return (a%b + b) % b
+}
+
+func (tl TimeLord) dateExcluded(nowInLoc time.Time) bool {
+	for _, excluded := range tl.ExcludeDates {
+		if excluded.Contains(nowInLoc) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (tl TimeLord) holiday(nowInLoc time.Time) bool {
+	for _, country := range tl.SkipHolidays {
+		if (*holidays.Country)(&country).IsHoliday(nowInLoc) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// weekdayMatches compares against days, honoring any ordinal such as "2nd
+// Tuesday" or "last Friday" within the month.
+func (tl TimeLord) weekdayMatches(days []models.Weekday, nowInLoc time.Time) bool {
+	if len(days) == 0 {
+		return true
+	}
+
+	daysInMonth := time.Date(nowInLoc.Year(), nowInLoc.Month()+1, 0, 0, 0, 0, 0, tl.loc()).Day()
+
+	for _, day := range days {
+		if day.Day() != nowInLoc.Weekday() {
+			continue
+		}
+
+		switch ordinal := day.Ordinal(); {
+		case ordinal == 0:
+			return true
+		case ordinal > 0 && (nowInLoc.Day()-1)/7+1 == ordinal:
+			return true
+		case ordinal < 0 && (daysInMonth-nowInLoc.Day())/7+1 == -ordinal:
+			return true
+		}
+	}
+
+	return false
+}
+
+// dayOfMonthMatches compares against DaysOfMonth, where negative values
+// count back from the end of the month (-1 is the last day).
+func (tl TimeLord) dayOfMonthMatches(nowInLoc time.Time) bool {
+	if len(tl.DaysOfMonth) == 0 {
+		return true
+	}
+
+	daysInMonth := time.Date(nowInLoc.Year(), nowInLoc.Month()+1, 0, 0, 0, 0, 0, tl.loc()).Day()
+
+	for _, day := range tl.DaysOfMonth {
+		if day == nowInLoc.Day() || day == nowInLoc.Day()-daysInMonth-1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// businessDayMatches compares the position of nowInLoc among the business
+// days of its month against BusinessDays, where negative values count back
+// from the end of the month (-1 is the last business day).
+func (tl TimeLord) businessDayMatches(nowInLoc time.Time) bool {
+	if len(tl.BusinessDays) == 0 {
+		return true
+	}
+
+	if !tl.isBusinessDay(nowInLoc) {
+		return false
+	}
+
+	position, total := 0, 0
+	daysInMonth := time.Date(nowInLoc.Year(), nowInLoc.Month()+1, 0, 0, 0, 0, 0, tl.loc()).Day()
+	for day := 1; day <= daysInMonth; day++ {
+		if tl.isBusinessDay(time.Date(nowInLoc.Year(), nowInLoc.Month(), day, 12, 0, 0, 0, tl.loc())) {
+			total++
+			if day <= nowInLoc.Day() {
+				position++
+			}
+		}
+	}
+
+	for _, day := range tl.BusinessDays {
+		if day == position || day == position-total-1 {
 			return true
 		}
 	}
@@ -159,34 +471,500 @@ func (tl TimeLord) daysMatch(now time.Time) bool {
 	return false
 }
 
-func (tl TimeLord) LatestRangeBefore(reference time.Time) (time.Time, time.Time) {
+// isBusinessDay reports whether the day of nowInLoc is a weekday that is
+// neither excluded nor a holiday.
+func (tl TimeLord) isBusinessDay(nowInLoc time.Time) bool {
+	if nowInLoc.Weekday() == time.Saturday || nowInLoc.Weekday() == time.Sunday {
+		return false
+	}
+
+	return !tl.dateExcluded(nowInLoc) && !tl.holiday(nowInLoc)
+}
+
+func (tl TimeLord) monthMatches(nowInLoc time.Time) bool {
+	if len(tl.Months) == 0 {
+		return true
+	}
+
+	monthInLoc := models.Month(nowInLoc.Month())
+
+	for _, month := range tl.Months {
+		if month == monthInLoc {
+			return true
+		}
+	}
+
+	return false
+}
+
+// weekMatches compares the ISO week of nowInLoc against Weeks and
+// WeekParity.
+func (tl TimeLord) weekMatches(nowInLoc time.Time) bool {
+	_, week := nowInLoc.ISOWeek()
+
+	switch tl.WeekParity {
+	case "even":
+		if week%2 != 0 {
+			return false
+		}
+	case "odd":
+		if week%2 == 0 {
+			return false
+		}
+	}
 
+	return len(tl.Weeks) == 0 || slices.Contains(tl.Weeks, week)
+}
+
+// allowed reports whether t is permitted by the calendars and outside every
+// blackout.
+func (tl TimeLord) allowed(t time.Time) bool {
+	return tl.calendarsAllow(t) && !tl.blackedOut(t)
+}
+
+// firstAllowed returns the first time from start until stop, to the minute,
+// that is allowed. A range whose start is blacked out is scheduled for when
+// the blackout ends, as that is when Check fires for it.
+func (tl TimeLord) firstAllowed(start, stop time.Time) (time.Time, bool) {
+	for t := start; t.Before(stop); {
+		if until, blackedOut := tl.blackedOutUntil(t); blackedOut {
+			t = until
+			continue
+		}
+
+		if tl.calendarsAllow(t) {
+			return t, true
+		}
+
+		t = t.Add(time.Minute)
+	}
+
+	return time.Time{}, false
+}
+
+// blackedOut reports whether t falls within an occurrence of any blackout.
+func (tl TimeLord) blackedOut(t time.Time) bool {
+	_, blackedOut := tl.blackedOutUntil(t)
+	return blackedOut
+}
+
+// blackedOutUntil returns the end of the occurrence of a blackout that t
+// falls within, if any. A blackout's days and dates apply to the day on
+// which it starts.
+func (tl TimeLord) blackedOutUntil(t time.Time) (time.Time, bool) {
+	for _, blackout := range tl.Blackouts {
+		r, found := tl.windowRangeBefore(models.Window{Start: blackout.Start, Stop: blackout.Stop}, t)
+		if !found || !t.Before(r.Stop) || !tl.weekdayMatches(blackout.Days, r.Start) {
+			continue
+		}
+
+		onDate := func(dates models.DateRange) bool { return dates.Contains(r.Start) }
+		if len(blackout.Dates) > 0 && !slices.ContainsFunc(blackout.Dates, onDate) {
+			continue
+		}
+
+		return r.Stop, true
+	}
+
+	return time.Time{}, false
+}
+
+// calendarsAllow reports whether t is outside every blackout calendar event
+// and, if any allow calendars are configured, inside one of their events.
+func (tl TimeLord) calendarsAllow(t time.Time) bool {
+	for _, calendar := range tl.BlackoutCalendars {
+		if calendar.Contains(t) {
+			return false
+		}
+	}
+
+	if len(tl.AllowCalendars) == 0 {
+		return true
+	}
+
+	for _, calendar := range tl.AllowCalendars {
+		if calendar.Contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// latestOccurrenceBefore returns the most recent cron or rrule occurrence
+// at or before reference, in the configured location, that also satisfies
+// days and start_after.
+func (tl TimeLord) latestOccurrenceBefore(reference time.Time) time.Time {
+	schedule := tl.occurrences()
+
+	// bounded so that days and calendars which can never match terminate
+	earliest := reference.AddDate(0, 0, -MAX_DAYS_SEARCHED)
+
+	fired := schedule.Prev(reference.In(tl.loc()))
+	for !fired.IsZero() && !tl.occurrenceMatches(fired) {
+		if fired.Before(earliest) || (tl.StartAfter != nil && fired.Before(tl.startAfterInLoc())) {
+			return time.Time{}
+		}
+
+		if !tl.daysMatch(fired) {
+			// no occurrence on this day can match, so skip to the day before
+			firedInLoc := fired.In(tl.loc())
+			day := time.Date(firedInLoc.Year(), firedInLoc.Month(), firedInLoc.Day(), 0, 0, 0, 0, tl.loc())
+			fired = schedule.Prev(day.Add(-time.Second))
+			continue
+		}
+
+		fired = schedule.Prev(fired.Add(-time.Second))
+	}
+
+	return fired
+}
+
+func (tl TimeLord) occurrenceMatches(fired time.Time) bool {
+	if tl.StartAfter != nil && fired.Before(tl.startAfterInLoc()) {
+		return false
+	}
+
+	return tl.daysMatch(fired) && tl.allowed(fired)
+}
+
+// occurrences returns the configured cron or rrule schedule, or nil if
+// neither is set. A recurrence rule is anchored on start_after as its
+// DTSTART.
+func (tl TimeLord) occurrences() occurrences {
+	switch {
+	case tl.Cron != nil:
+		return (*cron.Schedule)(tl.Cron)
+	case tl.RRule != nil && tl.StartAfter != nil:
+		return (*rrule.Rule)(tl.RRule).Recurrence(tl.startAfterInLoc())
+	}
+	return nil
+}
+
+func (tl TimeLord) startAfterInLoc() time.Time {
+	return tl.wallClockInLoc(time.Time(*tl.StartAfter))
+}
+
+func (tl TimeLord) stopAfterInLoc() time.Time {
+	return tl.wallClockInLoc(time.Time(*tl.StopAfter))
+}
+
+// wallClockInLoc reinterprets the date and time of day of t in the
+// configured location.
+func (tl TimeLord) wallClockInLoc(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(),
+		t.Hour(), t.Minute(), t.Second(), 0, tl.loc())
+}
+
+// untilStopAfter limits reference to stop_after, so that nothing after it is
+// scheduled.
+func (tl TimeLord) untilStopAfter(reference time.Time) time.Time {
+	if tl.StopAfter != nil && reference.After(tl.stopAfterInLoc()) {
+		return tl.stopAfterInLoc()
+	}
+	return reference
+}
+
+// Range is one occurrence of a daily window, from Start up to but excluding
+// Stop. Versions are emitted every Interval within it, or once at Start if
+// Interval is nil.
+type Range struct {
+	Start    time.Time
+	Stop     time.Time
+	Interval *models.Interval
+}
+
+// RangesBefore iterates over the ranges of every window that start at or
+// before reference, most recent first. Ranges on days excluded by their
+// window's days are skipped.
+func (tl TimeLord) RangesBefore(reference time.Time) iter.Seq[Range] {
+	return func(yield func(Range) bool) {
+		day := reference.In(tl.loc())
+		for searched := 0; searched <= MAX_DAYS_SEARCHED; searched++ {
+			ranges := tl.rangesAt(day)
+			for i := len(ranges) - 1; i >= 0; i-- {
+				if !yield(ranges[i]) {
+					return
+				}
+			}
+			day = day.AddDate(0, 0, -1)
+		}
+	}
+}
+
+func (tl TimeLord) latestRange(reference time.Time) (Range, bool) {
+	for r := range tl.RangesBefore(reference) {
+		return r, true
+	}
+	return Range{}, false
+}
+
+// rangesAt returns, for each window, its most recent range starting at or
+// before reference, ordered by start.
+func (tl TimeLord) rangesAt(reference time.Time) []Range {
+	var ranges []Range
+	for _, window := range tl.windows() {
+		r, found := tl.windowRangeBefore(window, reference)
+		if found && tl.weekdayMatches(window.Days, r.Start) {
+			ranges = append(ranges, r)
+		}
+	}
+
+	slices.SortStableFunc(ranges, func(a, b Range) int { return a.Start.Compare(b.Start) })
+
+	return ranges
+}
+
+// rangesOn returns the ranges of every window starting on the given day,
+// ordered by start.
+func (tl TimeLord) rangesOn(day time.Time) []Range {
+	var ranges []Range
+	for _, window := range tl.windows() {
+		for _, r := range tl.windowRangesOn(window, day) {
+			if tl.weekdayMatches(window.Days, r.Start) {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+
+	slices.SortStableFunc(ranges, func(a, b Range) int { return a.Start.Compare(b.Start) })
+
+	return ranges
+}
+
+// windows returns the configured windows, one window per day of Schedule,
+// or a single window made of Start, Stop and Interval if there are neither.
+func (tl TimeLord) windows() []models.Window {
+	var windows []models.Window
+	switch {
+	case len(tl.Schedule) > 0:
+		for _, day := range slices.Sorted(maps.Keys(tl.Schedule)) {
+			daySchedule := tl.Schedule[day]
+			windows = append(windows, models.Window{
+				Start:    daySchedule.Start,
+				Stop:     daySchedule.Stop,
+				Days:     []models.Weekday{day},
+				Interval: daySchedule.Interval,
+			})
+		}
+	case len(tl.Windows) > 0:
+		windows = slices.Clone(tl.Windows)
+	default:
+		return []models.Window{{Start: tl.Start, Stop: tl.Stop, Interval: tl.Interval}}
+	}
+
+	for i := range windows {
+		if windows[i].Interval == nil {
+			windows[i].Interval = tl.Interval
+		}
+	}
+
+	return windows
+}
+
+func (tl TimeLord) windowRangeBefore(window models.Window, reference time.Time) (Range, bool) {
+	refInLoc := reference.In(tl.loc())
+	day := time.Date(refInLoc.Year(), refInLoc.Month(), refInLoc.Day(), 0, 0, 0, 0, tl.loc())
+
+	ranges := tl.windowRangesOn(window, day)
+	for i := len(ranges) - 1; i >= 0; i-- {
+		if !ranges[i].Start.After(refInLoc) {
+			return ranges[i], true
+		}
+	}
+
+	ranges = tl.windowRangesOn(window, day.AddDate(0, 0, -1))
+	if len(ranges) == 0 {
+		return Range{}, false
+	}
+
+	return ranges[len(ranges)-1], true
+}
+
+// windowRangesOn returns the ranges of window starting on the given day.
+// There is usually one, but none if its start is skipped, and two if its
+// start is repeated by a DST transition and the policy is to run twice.
+func (tl TimeLord) windowRangesOn(window models.Window, day time.Time) []Range {
 	tlStart := DEFAULT_TIME_OF_DAY
-	if tl.Start != nil {
-		tlStart = *tl.Start
+	if window.Start != nil {
+		tlStart = *window.Start
 	}
 	tlStop := DEFAULT_TIME_OF_DAY
-	if tl.Stop != nil {
-		tlStop = *tl.Stop
+	if window.Stop != nil {
+		tlStop = *window.Stop
 	}
 
-	refInLoc := reference.In(tl.loc())
+	// a range ends the first time the clock shows its stop after its start,
+	// and is not dropped because its stop is skipped
+	stopPolicy := tl.DSTPolicy
+	if stopPolicy != "" {
+		stopPolicy = models.DST_RUN_TWICE
+	}
+
+	var ranges []Range
+	for _, start := range tl.timesOn(tlStart, day, tl.DSTPolicy) {
+		stop, found := firstAfter(tl.timesOn(tlStop, day, stopPolicy), start)
+		if !found {
+			stop, found = firstAfter(tl.timesOn(tlStop, day.AddDate(0, 0, 1), stopPolicy), start)
+		}
+		if !found {
+			continue
+		}
+
+		ranges = append(ranges, Range{Start: start, Stop: stop, Interval: window.Interval})
+	}
 
-	start := time.Date(refInLoc.Year(), refInLoc.Month(), refInLoc.Day(),
-		tlStart.Hour(), tlStart.Minute(), 0, 0, tl.loc())
+	return ranges
+}
+
+// firstAfter returns the first of the sorted times that is after t.
+func firstAfter(times []time.Time, t time.Time) (time.Time, bool) {
+	for _, candidate := range times {
+		if candidate.After(t) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
 
-	if start.After(refInLoc) {
-		start = start.AddDate(0, 0, -1)
+// timesOn returns the times at which tod occurs on the given day in the
+// configured location. Times relative to sunrise or sunset are rounded to
+// the minute, and do not occur on days the sun does not rise or set.
+func (tl TimeLord) timesOn(tod models.TimeOfDay, day time.Time, policy models.DSTPolicy) []time.Time {
+	var event time.Time
+	var found bool
+	switch tod.Solar {
+	case models.SUNRISE:
+		event, found = solar.Sunrise(day, tl.Latitude, tl.Longitude)
+	case models.SUNSET:
+		event, found = solar.Sunset(day, tl.Latitude, tl.Longitude)
+	default:
+		wall := time.Date(day.Year(), day.Month(), day.Day(), tod.Hour(), tod.Minute(), 0, 0, time.UTC)
+		return tl.resolve(wall, policy)
 	}
 
-	stop := time.Date(start.Year(), start.Month(), start.Day(),
-		tlStop.Hour(), tlStop.Minute(), 0, 0, tl.loc())
+	if !found {
+		return nil
+	}
+
+	return []time.Time{event.Add(tod.Offset).Round(time.Minute).In(tl.loc())}
+}
+
+// wallClock returns the time shown by a clock in the configured location at
+// t, as a time in UTC so that it can be stepped without crossing DST
+// transitions.
+func (tl TimeLord) wallClock(t time.Time) time.Time {
+	t = t.In(tl.loc())
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
 
-	if !stop.After(start) {
-		stop = stop.AddDate(0, 0, 1)
+// resolve returns the times at which a clock in the configured location
+// shows wall. A DST transition can skip a wall clock time or repeat it, in
+// which case the policy decides which times, if any, it resolves to. Without
+// a policy, it resolves to a single time on either side of the transition.
+func (tl TimeLord) resolve(wall time.Time, policy models.DSTPolicy) []time.Time {
+	loc := tl.loc()
+	if policy == "" {
+		return []time.Time{time.Date(wall.Year(), wall.Month(), wall.Day(),
+			wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)}
 	}
 
-	return start, stop
+	// the offsets in effect a day either side of wall; a transition between
+	// them is what can skip or repeat it
+	_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(24 * time.Hour).In(loc).Zone()
+
+	var times []time.Time
+	for _, offset := range []int{before, after} {
+		t := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if _, actual := t.Zone(); actual == offset && !slices.ContainsFunc(times, t.Equal) {
+			times = append(times, t)
+		}
+	}
+
+	switch len(times) {
+	case 0:
+		if policy == models.DST_SKIP {
+			return nil
+		}
+		// shifted later by the length of the skipped time
+		return []time.Time{wall.Add(-time.Duration(before) * time.Second).In(loc)}
+	case 2:
+		slices.SortFunc(times, time.Time.Compare)
+		switch policy {
+		case models.DST_SHIFT_FORWARD:
+			return times[1:]
+		case models.DST_RUN_TWICE:
+			return times
+		default:
+			return times[:1]
+		}
+	}
+
+	return times
+}
+
+// onWallClock reports whether intervals are stepped on the wall clock in the
+// configured location rather than in elapsed time.
+func (tl TimeLord) onWallClock() bool {
+	return tl.DSTPolicy != "" || tl.IntervalAnchor != ""
+}
+
+// intervalTimes iterates over the times of r's interval, stepping from
+// origin, or from where IntervalAnchor counts them from if it is set. With a DST
+// policy or alignment, the interval is stepped on the wall clock and each time
+// resolved by the policy; otherwise it is stepped in elapsed time.
+func (tl TimeLord) intervalTimes(r Range, origin time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		tlDuration := time.Duration(*r.Interval)
+
+		if !tl.onWallClock() {
+			intervalTime := origin
+			for intervalTime.Before(r.Start) {
+				intervalTime = intervalTime.Add(tlDuration)
+			}
+
+			for ; intervalTime.Before(r.Stop); intervalTime = intervalTime.Add(tlDuration) {
+				if !yield(intervalTime) {
+					return
+				}
+			}
+			return
+		}
+
+		from := tl.wallClock(origin)
+		switch start := tl.wallClock(r.Start); tl.IntervalAnchor {
+		case models.INTERVAL_ANCHOR_WINDOW_START:
+			from = start
+		case models.INTERVAL_ANCHOR_LOCAL_MIDNIGHT:
+			from = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		case models.INTERVAL_ANCHOR_EPOCH:
+			sinceEpoch := start.Sub(time.Unix(0, 0)) % tlDuration
+			if sinceEpoch < 0 {
+				sinceEpoch += tlDuration
+			}
+			from = start.Add(-sinceEpoch)
+		}
+
+		var intervalTimes []time.Time
+		stop := tl.wallClock(r.Stop)
+		for wall := from; !wall.After(stop); wall = wall.Add(tlDuration) {
+			for _, intervalTime := range tl.resolve(wall, tl.DSTPolicy) {
+				if !intervalTime.Before(r.Start) && intervalTime.Before(r.Stop) {
+					intervalTimes = append(intervalTimes, intervalTime)
+				}
+			}
+		}
+
+		// repeated times interleave, and a time shifted forward can land on
+		// the next one
+		slices.SortFunc(intervalTimes, time.Time.Compare)
+		for _, intervalTime := range slices.CompactFunc(intervalTimes, time.Time.Equal) {
+			if !yield(intervalTime) {
+				return
+			}
+		}
+	}
 }
 
 func (tl TimeLord) loc() *time.Location {
@@ -1,13 +1,19 @@
 package lord_test
 
 import (
+	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/concourse/time-resource/cron"
+	"github.com/concourse/time-resource/ics"
 	"github.com/concourse/time-resource/lord"
 	"github.com/concourse/time-resource/models"
+	"github.com/concourse/time-resource/rrule"
 )
 
 type expectedTime struct {
@@ -17,17 +23,58 @@ type expectedTime struct {
 	weekday time.Weekday
 }
 
-type testCase struct {
+type testWindow struct {
+	start    string
+	stop     string
+	days     []time.Weekday
 	interval string
+}
 
-	location string
+type testBlackout struct {
+	start string
+	stop  string
+	days  []time.Weekday
+	dates []string
+}
+
+type testCase struct {
+	interval       string
+	intervalAnchor models.IntervalAnchor
+
+	location  string
+	latitude  float64
+	longitude float64
 
 	start string
 	stop  string
 
-	days []time.Weekday
+	windows  []testWindow
+	schedule map[time.Weekday]testWindow
+
+	days         []time.Weekday
+	nthDays      []string
+	daysOfMonth  []int
+	businessDays []int
+	months       []time.Month
+	weeks        []int
+	weekParity   string
+
+	excludeDates []string
+	skipHolidays []string
+	blackouts    []testBlackout
+
+	blackoutEvents []string
+	allowEvents    []string
+
+	cron  string
+	rrule string
 
 	start_after string
+	stop_after  string
+
+	everyNDays  int
+	everyNWeeks int
+	anchor      string
 	prev        string
 	prevDay     time.Weekday
 
@@ -36,6 +83,7 @@ type testCase struct {
 	nowDay    time.Weekday
 
 	result bool
+	reason lord.Reason
 	latest expectedTime
 	list   []expectedTime
 }
@@ -54,6 +102,9 @@ func (tc testCase) Run() {
 		tl.Location = (*models.Location)(loc)
 	}
 
+	tl.Latitude = tc.latitude
+	tl.Longitude = tc.longitude
+
 	var format string
 	if tl.Location != nil {
 		format = exampleFormatWithoutTZ
@@ -68,24 +119,51 @@ func (tc testCase) Run() {
 		tl.StartAfter = &startAfterModel
 	}
 
-	if tc.start != "" {
-		tc.start += " 2018"
-		startTime, err := time.Parse(format, tc.start)
+	if tc.stop_after != "" {
+		stopAfter, err := time.Parse(iso8601Format, tc.stop_after)
 		Expect(err).NotTo(HaveOccurred())
+		stopAfterModel := models.StopAfter(stopAfter.UTC())
+		tl.StopAfter = &stopAfterModel
+	}
+
+	tl.Weeks = tc.weeks
+	tl.WeekParity = tc.weekParity
+
+	tl.EveryNDays = tc.everyNDays
+	tl.EveryNWeeks = tc.everyNWeeks
+
+	if tc.anchor != "" {
+		anchor, err := time.Parse(time.DateOnly, tc.anchor)
+		Expect(err).NotTo(HaveOccurred())
+		tl.Anchor = (*models.Anchor)(&anchor)
+	}
 
-		start := models.NewTimeOfDay(startTime.UTC())
+	if tc.start != "" {
+		start := timeOfDay(tc.start, format)
 		tl.Start = &start
 	}
 
 	if tc.stop != "" {
-		tc.stop += " 2018"
-		stopTime, err := time.Parse(format, tc.stop)
-		Expect(err).NotTo(HaveOccurred())
-
-		stop := models.NewTimeOfDay(stopTime.UTC())
+		stop := timeOfDay(tc.stop, format)
 		tl.Stop = &stop
 	}
 
+	for _, w := range tc.windows {
+		tl.Windows = append(tl.Windows, w.window(format))
+	}
+
+	if tc.schedule != nil {
+		tl.Schedule = models.Schedule{}
+		for day, w := range tc.schedule {
+			window := w.window(format)
+			tl.Schedule[models.Weekday(day)] = models.DaySchedule{
+				Start:    window.Start,
+				Stop:     window.Stop,
+				Interval: window.Interval,
+			}
+		}
+	}
+
 	if tc.interval != "" {
 		interval, err := time.ParseDuration(tc.interval)
 		Expect(err).NotTo(HaveOccurred())
@@ -93,11 +171,75 @@ func (tc testCase) Run() {
 		tl.Interval = (*models.Interval)(&interval)
 	}
 
+	tl.IntervalAnchor = tc.intervalAnchor
+
+	if tc.cron != "" {
+		schedule, err := cron.Parse(tc.cron)
+		Expect(err).NotTo(HaveOccurred())
+
+		tl.Cron = (*models.Cron)(schedule)
+	}
+
+	if tc.rrule != "" {
+		rule, err := rrule.Parse(tc.rrule)
+		Expect(err).NotTo(HaveOccurred())
+
+		tl.RRule = (*models.RRule)(rule)
+	}
+
 	tl.Days = make([]models.Weekday, len(tc.days))
 	for i, d := range tc.days {
 		tl.Days[i] = models.Weekday(d)
 	}
 
+	for _, d := range tc.nthDays {
+		nthDay, err := models.ParseNthWeekday(d)
+		Expect(err).NotTo(HaveOccurred())
+
+		tl.Days = append(tl.Days, nthDay)
+	}
+
+	tl.DaysOfMonth = tc.daysOfMonth
+	tl.BusinessDays = tc.businessDays
+
+	tl.Months = make([]models.Month, len(tc.months))
+	for i, m := range tc.months {
+		tl.Months[i] = models.Month(m)
+	}
+
+	tl.ExcludeDates = make([]models.DateRange, len(tc.excludeDates))
+	for i, d := range tc.excludeDates {
+		err := json.Unmarshal([]byte(strconv.Quote(d)), &tl.ExcludeDates[i])
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	tl.SkipHolidays = make([]models.HolidayCountry, len(tc.skipHolidays))
+	for i, c := range tc.skipHolidays {
+		err := json.Unmarshal([]byte(strconv.Quote(c)), &tl.SkipHolidays[i])
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	for _, b := range tc.blackouts {
+		window := testWindow{start: b.start, stop: b.stop, days: b.days}.window(format)
+		blackout := models.Blackout{Start: window.Start, Stop: window.Stop, Days: window.Days}
+
+		blackout.Dates = make([]models.DateRange, len(b.dates))
+		for i, d := range b.dates {
+			err := json.Unmarshal([]byte(strconv.Quote(d)), &blackout.Dates[i])
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		tl.Blackouts = append(tl.Blackouts, blackout)
+	}
+
+	if len(tc.blackoutEvents) > 0 {
+		tl.BlackoutCalendars = []*ics.Calendar{calendar(tl, tc.blackoutEvents)}
+	}
+
+	if len(tc.allowEvents) > 0 {
+		tl.AllowCalendars = []*ics.Calendar{calendar(tl, tc.allowEvents)}
+	}
+
 	now, err := time.Parse(exampleFormatWithTZ, tc.now+" 2018")
 	Expect(err).NotTo(HaveOccurred())
 
@@ -118,7 +260,10 @@ func (tc testCase) Run() {
 	}
 
 	result := tl.Check(now.UTC())
-	Expect(result).To(Equal(tc.result))
+	Expect(result.Fire).To(Equal(tc.result))
+	if tc.reason != "" {
+		Expect(result.Reason).To(Equal(tc.reason))
+	}
 
 	latest := tl.Latest(now.UTC())
 	Expect(latest.IsZero()).To(Equal(tc.latest.isZero))
@@ -143,6 +288,69 @@ func (tc testCase) Run() {
 	}
 }
 
+func (w testWindow) window(format string) models.Window {
+	var window models.Window
+
+	if w.start != "" {
+		start := timeOfDay(w.start, format)
+		window.Start = &start
+	}
+
+	if w.stop != "" {
+		stop := timeOfDay(w.stop, format)
+		window.Stop = &stop
+	}
+
+	for _, d := range w.days {
+		window.Days = append(window.Days, models.Weekday(d))
+	}
+
+	if w.interval != "" {
+		interval, err := time.ParseDuration(w.interval)
+		Expect(err).NotTo(HaveOccurred())
+
+		window.Interval = (*models.Interval)(&interval)
+	}
+
+	return window
+}
+
+// timeOfDay parses a clock time in the given format, or a time relative to
+// sunrise or sunset such as "sunset-1h".
+func timeOfDay(value string, format string) models.TimeOfDay {
+	var tod models.TimeOfDay
+	if strings.HasPrefix(value, "sun") {
+		err := json.Unmarshal([]byte(strconv.Quote(value)), &tod)
+		Expect(err).NotTo(HaveOccurred())
+		return tod
+	}
+
+	t, err := time.Parse(format, value+" 2018")
+	Expect(err).NotTo(HaveOccurred())
+
+	return models.NewTimeOfDay(t.UTC())
+}
+
+// calendar builds an iCalendar with one VEVENT per entry of events, each
+// given as the event's properties separated by newlines.
+func calendar(tl lord.TimeLord, events []string) *ics.Calendar {
+	content := "BEGIN:VCALENDAR\n"
+	for _, event := range events {
+		content += "BEGIN:VEVENT\n" + event + "\nEND:VEVENT\n"
+	}
+	content += "END:VCALENDAR\n"
+
+	loc := time.UTC
+	if tl.Location != nil {
+		loc = (*time.Location)(tl.Location)
+	}
+
+	cal, err := ics.Parse(strings.NewReader(content), loc)
+	Expect(err).NotTo(HaveOccurred())
+
+	return cal
+}
+
 var _ = DescribeTable("A range without a previous time", (testCase).Run,
 	Entry("between the start and stop time", testCase{
 		start:  "2:00 AM +0000",
@@ -452,6 +660,24 @@ var _ = DescribeTable("A range with an interval and a previous time", (testCase)
 		result: false,
 		latest: expectedTime{hour: 13, minute: 2},
 	}),
+	Entry("with a start off the interval's grid", testCase{
+		interval: "1h",
+
+		start: "9:05 AM +0000",
+		stop:  "1:00 PM +0000",
+
+		prev: "9:05 AM +0000",
+		now:  "12:30 PM +0000",
+
+		result: true,
+		latest: expectedTime{hour: 12, minute: 5},
+		list: []expectedTime{
+			{hour: 9, minute: 5},
+			{hour: 10, minute: 5},
+			{hour: 11, minute: 5},
+			{hour: 12, minute: 5},
+		},
+	}),
 	Entry("not between the start and stop time, elapsed", testCase{
 		interval: "2m",
 
@@ -539,6 +765,18 @@ var _ = DescribeTable("Start time with a range and interval", (testCase).Run,
 		result:      true,
 		latest:      expectedTime{hour: 13, minute: 0},
 	}),
+	Entry("start_after is between the previous time and now", testCase{
+		interval:    "2m",
+		start_after: "2018-01-07T12:03:00",
+		prev:        "12:00 PM +0000",
+		now:         "12:06 PM +0000",
+		result:      true,
+		latest:      expectedTime{hour: 12, minute: 6},
+		list: []expectedTime{
+			{hour: 12, minute: 4},
+			{hour: 12, minute: 6},
+		},
+	}),
 	Entry("start_after is in the past, now is before the range with location", testCase{
 		location:    "America/Indiana/Indianapolis",
 		start:       "1:00 PM",
@@ -558,3 +796,1524 @@ var _ = DescribeTable("Start time with a range and interval", (testCase).Run,
 		latest:      expectedTime{isZero: true},
 	}),
 )
+
+var _ = DescribeTable("A cron expression", (testCase).Run,
+	Entry("without a previous time", testCase{
+		cron:   "0 10 * * SUN",
+		now:    "11:00 AM +0000",
+		result: true,
+		latest: expectedTime{hour: 10},
+	}),
+	Entry("with a previous time before the latest firing", testCase{
+		cron:    "0 10 * * SUN",
+		prev:    "11:00 AM +0000",
+		prevDay: time.Saturday,
+		now:     "11:00 AM +0000",
+		result:  true,
+		latest:  expectedTime{hour: 10},
+		list: []expectedTime{
+			{hour: 10},
+		},
+	}),
+	Entry("with a previous time after the latest firing", testCase{
+		cron:   "0 10 * * SUN",
+		prev:   "10:30 AM +0000",
+		now:    "11:00 AM +0000",
+		result: false,
+		latest: expectedTime{hour: 10},
+		list:   []expectedTime{},
+	}),
+	Entry("listing every firing since the previous time", testCase{
+		cron:   "0 */2 * * *",
+		prev:   "6:00 AM +0000",
+		now:    "11:00 AM +0000",
+		result: true,
+		latest: expectedTime{hour: 10},
+		list: []expectedTime{
+			{hour: 6},
+			{hour: 8},
+			{hour: 10},
+		},
+	}),
+	Entry("in a given location", testCase{
+		location: "America/Indiana/Indianapolis",
+		cron:     "0 13 * * *",
+		prev:     "5:00 PM +0000",
+		now:      "6:05 PM +0000",
+		result:   true,
+		latest:   expectedTime{hour: 13},
+		list: []expectedTime{
+			{hour: 13},
+		},
+	}),
+	Entry("in a given location before the firing", testCase{
+		location: "America/Indiana/Indianapolis",
+		cron:     "0 13 * * *",
+		prev:     "6:00 PM +0000",
+		prevDay:  time.Saturday,
+		now:      "5:05 PM +0000",
+		result:   false,
+		latest:   expectedTime{hour: 13, weekday: time.Saturday},
+		list: []expectedTime{
+			{hour: 13, weekday: time.Saturday},
+		},
+	}),
+	Entry("combined with days", testCase{
+		cron:    "0 10 * * *",
+		days:    []time.Weekday{time.Sunday},
+		prev:    "9:00 AM +0000",
+		prevDay: time.Friday,
+		now:     "11:00 AM +0000",
+		result:  true,
+		latest:  expectedTime{hour: 10},
+		list: []expectedTime{
+			{hour: 10},
+		},
+	}),
+	Entry("combined with days, checked after the day has passed", testCase{
+		cron:    "0 23 * * *",
+		days:    []time.Weekday{time.Saturday},
+		prev:    "10:00 PM +0000",
+		prevDay: time.Saturday,
+		now:     "12:30 AM +0000",
+		result:  true,
+		reason:  lord.SCHEDULED_TIME,
+		latest:  expectedTime{hour: 23, weekday: time.Saturday},
+		list: []expectedTime{
+			{hour: 23, weekday: time.Saturday},
+		},
+	}),
+	Entry("combined with days that never match", testCase{
+		cron:        "0 * * * *",
+		daysOfMonth: []int{30},
+		months:      []time.Month{time.February},
+		now:         "11:00 AM +0000",
+		result:      false,
+		reason:      lord.NO_SCHEDULED_TIME,
+		latest:      expectedTime{isZero: true},
+	}),
+	Entry("combined with calendars that never allow it", testCase{
+		cron:        "0 * * * *",
+		allowEvents: []string{"DTSTART:20261201T000000Z\nDTEND:20261202T000000Z"},
+		now:         "11:00 AM +0000",
+		result:      false,
+		reason:      lord.NO_SCHEDULED_TIME,
+		latest:      expectedTime{isZero: true},
+	}),
+	Entry("before start_after", testCase{
+		cron:        "0 10 * * *",
+		start_after: "2018-01-07T10:30:00",
+		now:         "11:00 AM +0000",
+		result:      false,
+		latest:      expectedTime{isZero: true},
+	}),
+)
+
+var _ = DescribeTable("A recurrence rule", (testCase).Run,
+	Entry("without a previous time, after an occurrence", testCase{
+		rrule:       "FREQ=WEEKLY;BYDAY=SU",
+		start_after: "2018-01-01T10:00:00",
+		now:         "11:00 AM +0000",
+		result:      true,
+		latest:      expectedTime{hour: 10},
+	}),
+	Entry("with a previous time after the latest occurrence", testCase{
+		rrule:       "FREQ=WEEKLY;BYDAY=SU",
+		start_after: "2018-01-01T10:00:00",
+		prev:        "10:30 AM +0000",
+		now:         "11:00 AM +0000",
+		result:      false,
+		latest:      expectedTime{hour: 10},
+		list:        []expectedTime{},
+	}),
+	Entry("with a previous time before several occurrences", testCase{
+		rrule:       "FREQ=DAILY",
+		start_after: "2018-01-01T10:00:00",
+		prev:        "10:00 AM +0000",
+		prevDay:     time.Friday,
+		now:         "11:00 AM +0000",
+		result:      true,
+		latest:      expectedTime{hour: 10},
+		list: []expectedTime{
+			{hour: 10, weekday: time.Friday},
+			{hour: 10, weekday: time.Saturday},
+			{hour: 10},
+		},
+	}),
+	Entry("before DTSTART", testCase{
+		rrule:       "FREQ=DAILY",
+		start_after: "2018-01-10T10:00:00",
+		now:         "11:00 AM +0000",
+		result:      false,
+		latest:      expectedTime{isZero: true},
+	}),
+	Entry("in a given location", testCase{
+		location:    "America/Indiana/Indianapolis",
+		rrule:       "FREQ=MONTHLY;BYDAY=1SU",
+		start_after: "2018-01-01T13:00:00",
+		now:         "6:05 PM +0000",
+		result:      true,
+		latest:      expectedTime{hour: 13},
+	}),
+	Entry("in a given location on a non-matching day", testCase{
+		location:    "America/Indiana/Indianapolis",
+		rrule:       "FREQ=MONTHLY;BYDAY=2SU",
+		start_after: "2018-01-01T13:00:00",
+		prev:        "6:05 PM +0000",
+		now:         "6:05 PM +0000",
+		nowDay:      time.Monday,
+		result:      false,
+		latest:      expectedTime{isZero: true},
+	}),
+)
+
+var _ = DescribeTable("A range with days of month and months", (testCase).Run,
+	Entry("on a matching day of month", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		daysOfMonth: []int{1, 7},
+		now:         "3:00 AM +0000",
+		result:      true,
+		latest:      expectedTime{hour: 2},
+	}),
+	Entry("on a matching day of month counted from the end", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		daysOfMonth: []int{-25},
+		now:         "3:00 AM +0000",
+		result:      true,
+		latest:      expectedTime{hour: 2},
+	}),
+	Entry("not on a matching day of month", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		daysOfMonth: []int{8},
+		now:         "3:00 AM +0000",
+		result:      false,
+		latest:      expectedTime{isZero: true},
+	}),
+	Entry("in a matching month", testCase{
+		start:  "2:00 AM +0000",
+		stop:   "4:00 AM +0000",
+		months: []time.Month{time.January, time.March},
+		now:    "3:00 AM +0000",
+		result: true,
+		latest: expectedTime{hour: 2},
+	}),
+	Entry("not in a matching month", testCase{
+		start:  "2:00 AM +0000",
+		stop:   "4:00 AM +0000",
+		months: []time.Month{time.February},
+		now:    "3:00 AM +0000",
+		result: false,
+		latest: expectedTime{isZero: true},
+	}),
+	Entry("never matching", testCase{
+		interval:    "1h",
+		daysOfMonth: []int{31},
+		months:      []time.Month{time.February},
+		now:         "3:00 AM +0000",
+		result:      false,
+		latest:      expectedTime{isZero: true},
+	}),
+	Entry("with a previous time on an earlier matching day", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		daysOfMonth: []int{1, 7},
+		prev:        "3:00 AM +0000",
+		prevDay:     time.Monday,
+		now:         "3:00 AM +0000",
+		result:      true,
+		latest:      expectedTime{hour: 2},
+		list: []expectedTime{
+			{hour: 2},
+		},
+	}),
+	Entry("with an interval and a previous time on an earlier matching day", testCase{
+		interval:    "1h",
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		daysOfMonth: []int{1, -25},
+		prev:        "3:00 AM +0000",
+		prevDay:     time.Monday,
+		now:         "2:30 AM +0000",
+		result:      true,
+		latest:      expectedTime{hour: 2},
+		list: []expectedTime{
+			{hour: 3, weekday: time.Monday},
+			{hour: 2},
+		},
+	}),
+)
+
+var _ = DescribeTable("A range with ordinal weekdays", (testCase).Run,
+	Entry("on the matching occurrence of the weekday", testCase{
+		start:   "2:00 AM +0000",
+		stop:    "4:00 AM +0000",
+		nthDays: []string{"1st Sunday"},
+		now:     "3:00 AM +0000",
+		result:  true,
+		latest:  expectedTime{hour: 2},
+	}),
+	Entry("not on the matching occurrence of the weekday", testCase{
+		start:   "2:00 AM +0000",
+		stop:    "4:00 AM +0000",
+		nthDays: []string{"2nd Sunday"},
+		now:     "3:00 AM +0000",
+		result:  false,
+		latest:  expectedTime{isZero: true},
+	}),
+	Entry("not on the last occurrence of the weekday", testCase{
+		start:   "2:00 AM +0000",
+		stop:    "4:00 AM +0000",
+		nthDays: []string{"last Sunday"},
+		now:     "3:00 AM +0000",
+		result:  false,
+		latest:  expectedTime{isZero: true},
+	}),
+	Entry("on an occurrence counted from the end of the month", testCase{
+		start:   "2:00 AM +0000",
+		stop:    "4:00 AM +0000",
+		nthDays: []string{"-4 Sunday"},
+		now:     "3:00 AM +0000",
+		result:  true,
+		latest:  expectedTime{hour: 2},
+	}),
+	Entry("on the matching occurrence in a given location", testCase{
+		location: "America/Indiana/Indianapolis",
+		start:    "9:00 PM",
+		stop:     "11:00 PM",
+		nthDays:  []string{"first Monday"},
+		now:      "2:00 AM +0000",
+		nowDay:   time.Tuesday,
+		result:   true,
+		latest:   expectedTime{hour: 21, weekday: time.Monday},
+	}),
+	Entry("with a previous time and a list across the month", testCase{
+		interval: "1h",
+		start:    "2:00 AM +0000",
+		stop:     "3:00 AM +0000",
+		nthDays:  []string{"1st Monday", "1st Sunday"},
+		prev:     "2:00 AM +0000",
+		prevDay:  time.Monday,
+		now:      "2:30 AM +0000",
+		result:   true,
+		latest:   expectedTime{hour: 2},
+		list: []expectedTime{
+			{hour: 2, weekday: time.Monday},
+			{hour: 2},
+		},
+	}),
+)
+
+var _ = DescribeTable("A range with excluded dates", (testCase).Run,
+	Entry("on an excluded date", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		excludeDates: []string{"2018-01-07"},
+		now:          "3:00 AM +0000",
+		result:       false,
+		latest:       expectedTime{isZero: true},
+	}),
+	Entry("on a date that is not excluded", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		excludeDates: []string{"2018-01-06", "2018-01-08"},
+		now:          "3:00 AM +0000",
+		result:       true,
+		latest:       expectedTime{hour: 2},
+	}),
+	Entry("with an interval and a previous time before an excluded range", testCase{
+		interval:     "1h",
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		excludeDates: []string{"2018-01-05/2018-01-06"},
+		prev:         "3:00 AM +0000",
+		prevDay:      time.Thursday,
+		now:          "2:30 AM +0000",
+		result:       true,
+		latest:       expectedTime{hour: 2},
+		list: []expectedTime{
+			{hour: 3, weekday: time.Thursday},
+			{hour: 2},
+		},
+	}),
+	Entry("with the latest time before an excluded date", testCase{
+		interval:     "1h",
+		excludeDates: []string{"2018-01-07"},
+		prev:         "3:00 AM +0000",
+		prevDay:      time.Saturday,
+		now:          "2:30 AM +0000",
+		result:       false,
+		latest:       expectedTime{hour: 23, weekday: time.Saturday},
+		list: []expectedTime{
+			{hour: 3, weekday: time.Saturday},
+			{hour: 4, weekday: time.Saturday},
+			{hour: 5, weekday: time.Saturday},
+			{hour: 6, weekday: time.Saturday},
+			{hour: 7, weekday: time.Saturday},
+			{hour: 8, weekday: time.Saturday},
+			{hour: 9, weekday: time.Saturday},
+			{hour: 10, weekday: time.Saturday},
+			{hour: 11, weekday: time.Saturday},
+			{hour: 12, weekday: time.Saturday},
+			{hour: 13, weekday: time.Saturday},
+			{hour: 14, weekday: time.Saturday},
+			{hour: 15, weekday: time.Saturday},
+			{hour: 16, weekday: time.Saturday},
+			{hour: 17, weekday: time.Saturday},
+			{hour: 18, weekday: time.Saturday},
+			{hour: 19, weekday: time.Saturday},
+			{hour: 20, weekday: time.Saturday},
+			{hour: 21, weekday: time.Saturday},
+			{hour: 22, weekday: time.Saturday},
+			{hour: 23, weekday: time.Saturday},
+		},
+	}),
+	Entry("on an excluded date in a given location", testCase{
+		location:     "America/Indiana/Indianapolis",
+		start:        "9:00 PM",
+		stop:         "11:00 PM",
+		excludeDates: []string{"2018-01-01"},
+		now:          "2:00 AM +0000",
+		nowDay:       time.Tuesday,
+		result:       false,
+		latest:       expectedTime{isZero: true},
+	}),
+)
+
+var _ = DescribeTable("Calendars", (testCase).Run,
+	Entry("during a blackout event", testCase{
+		interval:       "1h",
+		blackoutEvents: []string{"DTSTART:20180107T020000Z\nDTEND:20180107T040000Z"},
+		now:            "3:00 AM +0000",
+		result:         false,
+		latest:         expectedTime{hour: 1},
+		list:           []expectedTime{},
+	}),
+	Entry("after a blackout event", testCase{
+		interval:       "1h",
+		blackoutEvents: []string{"DTSTART:20180107T020000Z\nDTEND:20180107T040000Z"},
+		prev:           "1:00 AM +0000",
+		now:            "4:30 AM +0000",
+		result:         true,
+		latest:         expectedTime{hour: 4},
+		list: []expectedTime{
+			{hour: 1},
+			{hour: 4},
+		},
+	}),
+	Entry("during a recurring blackout event", testCase{
+		start:          "2:00 AM +0000",
+		stop:           "4:00 AM +0000",
+		blackoutEvents: []string{"DTSTART;VALUE=DATE:20180101\nRRULE:FREQ=WEEKLY;BYDAY=SU"},
+		now:            "3:00 AM +0000",
+		result:         false,
+		latest:         expectedTime{isZero: true},
+	}),
+	Entry("during an allow event", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		allowEvents: []string{"DTSTART;VALUE=DATE:20180107"},
+		now:         "3:00 AM +0000",
+		result:      true,
+		latest:      expectedTime{hour: 2},
+	}),
+	Entry("outside every allow event", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		allowEvents: []string{"DTSTART;VALUE=DATE:20180108"},
+		now:         "3:00 AM +0000",
+		result:      false,
+		latest:      expectedTime{isZero: true},
+	}),
+	Entry("during an all-day allow event in a given location", testCase{
+		location:    "America/Indiana/Indianapolis",
+		start:       "9:00 PM",
+		stop:        "11:00 PM",
+		allowEvents: []string{"DTSTART;VALUE=DATE:20180101"},
+		now:         "2:00 AM +0000",
+		nowDay:      time.Tuesday,
+		result:      true,
+		latest:      expectedTime{hour: 21, weekday: time.Monday},
+	}),
+)
+
+var _ = DescribeTable("A range skipping holidays", (testCase).Run,
+	Entry("on a holiday", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		skipHolidays: []string{"US"},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Monday,
+		result:       false,
+		latest:       expectedTime{isZero: true},
+	}),
+	Entry("on a holiday of only one of the countries", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		skipHolidays: []string{"DE", "GB"},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Monday,
+		result:       false,
+		latest:       expectedTime{isZero: true},
+	}),
+	Entry("on a day that is not a holiday", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		skipHolidays: []string{"US", "GB", "DE"},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Tuesday,
+		result:       true,
+		latest:       expectedTime{hour: 2, weekday: time.Tuesday},
+	}),
+	Entry("with an interval on a holiday", testCase{
+		interval:     "1h",
+		skipHolidays: []string{"GB"},
+		now:          "10:30 AM +0000",
+		nowDay:       time.Monday,
+		result:       false,
+		latest:       expectedTime{isZero: true},
+	}),
+)
+
+var _ = DescribeTable("Multiple windows", (testCase).Run,
+	Entry("in the first window", testCase{
+		interval: "15m",
+		windows: []testWindow{
+			{start: "6:00 AM +0000", stop: "9:00 AM +0000"},
+			{start: "5:00 PM +0000", stop: "8:00 PM +0000"},
+		},
+		prev:   "6:30 AM +0000",
+		now:    "7:10 AM +0000",
+		result: true,
+		latest: expectedTime{hour: 7},
+		list: []expectedTime{
+			{hour: 6, minute: 30},
+			{hour: 6, minute: 45},
+			{hour: 7},
+		},
+	}),
+	Entry("between the windows", testCase{
+		interval: "15m",
+		windows: []testWindow{
+			{start: "6:00 AM +0000", stop: "9:00 AM +0000"},
+			{start: "5:00 PM +0000", stop: "8:00 PM +0000"},
+		},
+		prev:   "8:45 AM +0000",
+		now:    "12:00 PM +0000",
+		result: false,
+		latest: expectedTime{hour: 8, minute: 45},
+	}),
+	Entry("in the second window", testCase{
+		interval: "15m",
+		windows: []testWindow{
+			{start: "6:00 AM +0000", stop: "9:00 AM +0000"},
+			{start: "5:00 PM +0000", stop: "8:00 PM +0000"},
+		},
+		prev:   "8:45 AM +0000",
+		now:    "5:20 PM +0000",
+		result: true,
+		latest: expectedTime{hour: 17, minute: 15},
+		list: []expectedTime{
+			{hour: 8, minute: 45},
+			{hour: 17},
+			{hour: 17, minute: 15},
+		},
+	}),
+	Entry("in a window restricted to other days", testCase{
+		windows: []testWindow{
+			{start: "9:00 AM +0000", stop: "10:00 AM +0000", days: []time.Weekday{time.Saturday}},
+			{start: "12:00 PM +0000", stop: "1:00 PM +0000"},
+		},
+		now:    "9:30 AM +0000",
+		result: false,
+		latest: expectedTime{isZero: true},
+	}),
+	Entry("in a window not restricted to other days", testCase{
+		windows: []testWindow{
+			{start: "9:00 AM +0000", stop: "10:00 AM +0000", days: []time.Weekday{time.Saturday}},
+			{start: "12:00 PM +0000", stop: "1:00 PM +0000"},
+		},
+		now:    "12:30 PM +0000",
+		result: true,
+		latest: expectedTime{hour: 12},
+	}),
+	Entry("with an interval in only one window", testCase{
+		windows: []testWindow{
+			{start: "6:00 AM +0000", stop: "9:00 AM +0000", interval: "1h"},
+			{start: "5:00 PM +0000", stop: "8:00 PM +0000"},
+		},
+		prev:   "8:00 AM +0000",
+		now:    "5:05 PM +0000",
+		result: true,
+		latest: expectedTime{hour: 17},
+		list: []expectedTime{
+			{hour: 8},
+			{hour: 17},
+		},
+	}),
+	Entry("in overlapping windows", testCase{
+		interval: "30m",
+		windows: []testWindow{
+			{start: "6:00 AM +0000", stop: "9:00 AM +0000"},
+			{start: "8:00 AM +0000", stop: "10:00 AM +0000"},
+		},
+		prev:   "7:30 AM +0000",
+		now:    "8:40 AM +0000",
+		result: true,
+		latest: expectedTime{hour: 8, minute: 30},
+		list: []expectedTime{
+			{hour: 7, minute: 30},
+			{hour: 8},
+			{hour: 8, minute: 30},
+		},
+	}),
+)
+
+var weeklySchedule = map[time.Weekday]testWindow{
+	time.Monday:    {start: "8:00 AM +0000", stop: "6:00 PM +0000", interval: "4h"},
+	time.Tuesday:   {start: "8:00 AM +0000", stop: "6:00 PM +0000", interval: "4h"},
+	time.Wednesday: {start: "8:00 AM +0000", stop: "6:00 PM +0000", interval: "4h"},
+	time.Thursday:  {start: "8:00 AM +0000", stop: "6:00 PM +0000", interval: "4h"},
+	time.Friday:    {start: "8:00 AM +0000", stop: "6:00 PM +0000", interval: "4h"},
+	time.Saturday:  {start: "10:00 AM +0000", stop: "2:00 PM +0000"},
+}
+
+var _ = DescribeTable("A weekly schedule", (testCase).Run,
+	Entry("on a day without an entry", testCase{
+		schedule: weeklySchedule,
+		now:      "12:00 PM +0000",
+		result:   false,
+		latest:   expectedTime{isZero: true},
+	}),
+	Entry("within the window of the day", testCase{
+		schedule: weeklySchedule,
+		now:      "11:00 AM +0000",
+		nowDay:   time.Saturday,
+		result:   true,
+		latest:   expectedTime{hour: 10, weekday: time.Saturday},
+	}),
+	Entry("before the window of the day", testCase{
+		schedule: weeklySchedule,
+		now:      "9:00 AM +0000",
+		nowDay:   time.Saturday,
+		result:   false,
+		latest:   expectedTime{isZero: true},
+	}),
+	Entry("within the window of another day", testCase{
+		schedule: weeklySchedule,
+		now:      "12:00 PM +0000",
+		nowDay:   time.Tuesday,
+		result:   true,
+		latest:   expectedTime{hour: 12, weekday: time.Tuesday},
+	}),
+	Entry("with a day that has no start or stop", testCase{
+		schedule: map[time.Weekday]testWindow{time.Sunday: {}},
+		now:      "11:00 PM +0000",
+		result:   true,
+		latest:   expectedTime{hour: 0},
+	}),
+	Entry("with a previous time on a day with a different interval", testCase{
+		schedule: weeklySchedule,
+		prev:     "4:00 PM +0000",
+		prevDay:  time.Friday,
+		now:      "10:30 AM +0000",
+		nowDay:   time.Saturday,
+		result:   true,
+		latest:   expectedTime{hour: 10, weekday: time.Saturday},
+		list: []expectedTime{
+			{hour: 16, weekday: time.Friday},
+			{hour: 10, weekday: time.Saturday},
+		},
+	}),
+)
+
+var _ = DescribeTable("Blackouts", (testCase).Run,
+	Entry("during a blackout", testCase{
+		interval:  "10m",
+		blackouts: []testBlackout{{start: "2:00 AM +0000", stop: "3:00 AM +0000"}},
+		prev:      "1:50 AM +0000",
+		now:       "2:30 AM +0000",
+		result:    false,
+		latest:    expectedTime{hour: 1, minute: 50},
+	}),
+	Entry("after a blackout", testCase{
+		interval:  "10m",
+		blackouts: []testBlackout{{start: "2:00 AM +0000", stop: "3:00 AM +0000"}},
+		prev:      "1:50 AM +0000",
+		now:       "3:05 AM +0000",
+		result:    true,
+		latest:    expectedTime{hour: 3},
+		list: []expectedTime{
+			{hour: 1, minute: 50},
+			{hour: 3},
+		},
+	}),
+	Entry("during a blackout on its day", testCase{
+		interval:  "1h",
+		blackouts: []testBlackout{{start: "12:00 PM +0000", stop: "12:00 AM +0000", days: []time.Weekday{time.Friday}}},
+		prev:      "11:00 AM +0000",
+		prevDay:   time.Friday,
+		now:       "3:30 PM +0000",
+		nowDay:    time.Friday,
+		result:    false,
+		latest:    expectedTime{hour: 11, weekday: time.Friday},
+	}),
+	Entry("during the hours of a blackout on another day", testCase{
+		interval:  "1h",
+		blackouts: []testBlackout{{start: "12:00 PM +0000", stop: "12:00 AM +0000", days: []time.Weekday{time.Friday}}},
+		prev:      "2:00 PM +0000",
+		now:       "3:30 PM +0000",
+		result:    true,
+		latest:    expectedTime{hour: 15},
+		list: []expectedTime{
+			{hour: 14},
+			{hour: 15},
+		},
+	}),
+	Entry("covering the start of a window, before it ends", testCase{
+		start:     "8:00 AM +0000",
+		stop:      "10:00 AM +0000",
+		blackouts: []testBlackout{{start: "8:00 AM +0000", stop: "9:00 AM +0000"}},
+		now:       "8:30 AM +0000",
+		result:    false,
+		latest:    expectedTime{isZero: true},
+	}),
+	Entry("covering the start of a window, after it ends", testCase{
+		start:     "8:00 AM +0000",
+		stop:      "10:00 AM +0000",
+		blackouts: []testBlackout{{start: "8:00 AM +0000", stop: "9:00 AM +0000"}},
+		prev:      "9:00 AM +0000",
+		prevDay:   time.Saturday,
+		now:       "9:30 AM +0000",
+		result:    true,
+		latest:    expectedTime{hour: 9},
+		list:      []expectedTime{{hour: 9}},
+	}),
+	Entry("covering the start of a window, once it has fired", testCase{
+		start:     "8:00 AM +0000",
+		stop:      "10:00 AM +0000",
+		blackouts: []testBlackout{{start: "8:00 AM +0000", stop: "9:00 AM +0000"}},
+		prev:      "9:00 AM +0000",
+		now:       "9:30 AM +0000",
+		result:    false,
+		latest:    expectedTime{isZero: true},
+	}),
+	Entry("on a blacked out date", testCase{
+		start:     "2:00 AM +0000",
+		stop:      "4:00 AM +0000",
+		blackouts: []testBlackout{{dates: []string{"2018-01-07"}}},
+		now:       "3:00 AM +0000",
+		result:    false,
+		latest:    expectedTime{isZero: true},
+	}),
+	Entry("on a date that is not blacked out", testCase{
+		start:     "2:00 AM +0000",
+		stop:      "4:00 AM +0000",
+		blackouts: []testBlackout{{dates: []string{"2018-01-06"}}},
+		now:       "3:00 AM +0000",
+		result:    true,
+		latest:    expectedTime{hour: 2},
+	}),
+)
+
+var _ = DescribeTable("Stop time with a range and interval", (testCase).Run,
+	Entry("stop_after is in the past", testCase{
+		interval:   "2m",
+		start:      "1:00 PM +0000",
+		stop:       "3:00 PM +0000",
+		stop_after: "2018-01-06T00:00:00",
+		now:        "1:30 PM +0000",
+		result:     false,
+		latest:     expectedTime{isZero: true},
+	}),
+	Entry("stop_after is in the future", testCase{
+		interval:   "2m",
+		start:      "1:00 PM +0000",
+		stop:       "3:00 PM +0000",
+		stop_after: "2018-12-31T00:00:00",
+		now:        "1:30 PM +0000",
+		result:     true,
+		latest:     expectedTime{hour: 13, minute: 30},
+	}),
+	Entry("stop_after is exactly now", testCase{
+		interval:   "2m",
+		start:      "1:00 PM +0000",
+		stop:       "3:00 PM +0000",
+		stop_after: "2018-01-07T13:30:00",
+		now:        "1:30 PM +0000",
+		result:     true,
+		latest:     expectedTime{hour: 13, minute: 30},
+	}),
+	Entry("stop_after is within the range, with a previous time", testCase{
+		interval:   "2m",
+		start:      "1:00 PM +0000",
+		stop:       "3:00 PM +0000",
+		stop_after: "2018-01-07T14:00:00",
+		prev:       "1:54 PM +0000",
+		now:        "2:30 PM +0000",
+		result:     false,
+		latest:     expectedTime{hour: 14},
+		list: []expectedTime{
+			{hour: 13, minute: 54},
+			{hour: 13, minute: 56},
+			{hour: 13, minute: 58},
+			{hour: 14},
+		},
+	}),
+	Entry("stop_after is between cron occurrences", testCase{
+		cron:       "0 * * * *",
+		stop_after: "2018-01-07T10:30:00",
+		now:        "2:00 PM +0000",
+		result:     false,
+		latest:     expectedTime{hour: 10},
+	}),
+)
+
+var _ = DescribeTable("A cadence of days or weeks", (testCase).Run,
+	Entry("on a day of an every_n_days cadence", testCase{
+		start:      "2:00 AM +0000",
+		stop:       "4:00 AM +0000",
+		everyNDays: 3,
+		anchor:     "2018-01-01",
+		now:        "3:00 AM +0000",
+		result:     true,
+		latest:     expectedTime{hour: 2},
+	}),
+	Entry("on a day between an every_n_days cadence", testCase{
+		start:      "2:00 AM +0000",
+		stop:       "4:00 AM +0000",
+		everyNDays: 3,
+		anchor:     "2018-01-01",
+		now:        "3:00 AM +0000",
+		nowDay:     time.Saturday,
+		result:     false,
+		latest:     expectedTime{isZero: true},
+	}),
+	Entry("anchored on start_after", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		everyNDays:  3,
+		start_after: "2018-01-02T00:00:00",
+		now:         "3:00 AM +0000",
+		nowDay:      time.Friday,
+		result:      true,
+		latest:      expectedTime{hour: 2, weekday: time.Friday},
+	}),
+	Entry("anchored on start_after, between the cadence", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		everyNDays:  3,
+		start_after: "2018-01-02T00:00:00",
+		now:         "3:00 AM +0000",
+		result:      false,
+		latest:      expectedTime{isZero: true},
+	}),
+	Entry("in an active week of an every_n_weeks cadence", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		days:        []time.Weekday{time.Monday},
+		everyNWeeks: 2,
+		anchor:      "2017-12-18",
+		now:         "3:00 AM +0000",
+		nowDay:      time.Monday,
+		result:      true,
+		latest:      expectedTime{hour: 2, weekday: time.Monday},
+	}),
+	Entry("in an inactive week of an every_n_weeks cadence", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		days:        []time.Weekday{time.Monday},
+		everyNWeeks: 2,
+		anchor:      "2017-12-25",
+		now:         "3:00 AM +0000",
+		nowDay:      time.Monday,
+		result:      false,
+		latest:      expectedTime{isZero: true},
+	}),
+	Entry("with weeks counted from the Monday before the anchor", testCase{
+		start:       "2:00 AM +0000",
+		stop:        "4:00 AM +0000",
+		days:        []time.Weekday{time.Monday},
+		everyNWeeks: 2,
+		anchor:      "2017-12-20",
+		now:         "3:00 AM +0000",
+		nowDay:      time.Monday,
+		result:      true,
+		latest:      expectedTime{hour: 2, weekday: time.Monday},
+	}),
+	Entry("with an interval and a previous time before a skipped day", testCase{
+		interval:   "1h",
+		everyNDays: 2,
+		anchor:     "2018-01-01",
+		prev:       "11:00 PM +0000",
+		prevDay:    time.Friday,
+		now:        "12:30 AM +0000",
+		result:     true,
+		latest:     expectedTime{hour: 0},
+		list: []expectedTime{
+			{hour: 23, weekday: time.Friday},
+			{hour: 0},
+		},
+	}),
+)
+
+var _ = DescribeTable("A range with ISO weeks", (testCase).Run,
+	Entry("in an odd week", testCase{
+		start:      "2:00 AM +0000",
+		stop:       "4:00 AM +0000",
+		weekParity: "odd",
+		now:        "3:00 AM +0000",
+		result:     true,
+		latest:     expectedTime{hour: 2},
+	}),
+	Entry("not in an even week", testCase{
+		start:      "2:00 AM +0000",
+		stop:       "4:00 AM +0000",
+		weekParity: "even",
+		now:        "3:00 AM +0000",
+		result:     false,
+		latest:     expectedTime{isZero: true},
+	}),
+	Entry("in one of the weeks", testCase{
+		start:  "2:00 AM +0000",
+		stop:   "4:00 AM +0000",
+		weeks:  []int{1, 27},
+		now:    "3:00 AM +0000",
+		result: true,
+		latest: expectedTime{hour: 2},
+	}),
+	Entry("not in any of the weeks", testCase{
+		start:  "2:00 AM +0000",
+		stop:   "4:00 AM +0000",
+		weeks:  []int{2, 3},
+		now:    "3:00 AM +0000",
+		result: false,
+		latest: expectedTime{isZero: true},
+	}),
+	Entry("in a week that has already begun in the location", testCase{
+		location: "Asia/Tokyo",
+		weeks:    []int{2},
+		now:      "11:30 PM +0000",
+		result:   true,
+		latest:   expectedTime{hour: 0, weekday: time.Monday},
+	}),
+)
+
+var _ = DescribeTable("A range with business days of month", (testCase).Run,
+	Entry("on the first business day", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		businessDays: []int{1},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Monday,
+		result:       true,
+		latest:       expectedTime{hour: 2, weekday: time.Monday},
+	}),
+	Entry("on a holiday that would be the first business day", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		businessDays: []int{1},
+		skipHolidays: []string{"US"},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Monday,
+		result:       false,
+		latest:       expectedTime{isZero: true},
+	}),
+	Entry("on the first business day after a holiday", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		businessDays: []int{1},
+		skipHolidays: []string{"US"},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Tuesday,
+		result:       true,
+		latest:       expectedTime{hour: 2, weekday: time.Tuesday},
+	}),
+	Entry("on the third business day after an excluded date", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		businessDays: []int{3},
+		excludeDates: []string{"2018-01-03"},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Thursday,
+		result:       true,
+		latest:       expectedTime{hour: 2, weekday: time.Thursday},
+	}),
+	Entry("on a day that is not the last business day", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		businessDays: []int{-1},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Friday,
+		result:       false,
+		latest:       expectedTime{isZero: true},
+	}),
+	Entry("counting back from the last business day", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		businessDays: []int{-23},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Monday,
+		result:       true,
+		latest:       expectedTime{hour: 2, weekday: time.Monday},
+	}),
+	Entry("on a weekend", testCase{
+		start:        "2:00 AM +0000",
+		stop:         "4:00 AM +0000",
+		businessDays: []int{5, 6},
+		now:          "3:00 AM +0000",
+		nowDay:       time.Saturday,
+		result:       false,
+		latest:       expectedTime{isZero: true},
+	}),
+)
+
+var _ = DescribeTable("A range relative to sunrise and sunset", (testCase).Run,
+	Entry("between the offsets", testCase{
+		location:  "Europe/London",
+		latitude:  51.5074,
+		longitude: -0.1278,
+		start:     "sunrise+30m",
+		stop:      "sunset-1h",
+		now:       "12:00 PM +0000",
+		result:    true,
+		latest:    expectedTime{hour: 8, minute: 35},
+	}),
+	Entry("before the offset from sunrise", testCase{
+		location:  "Europe/London",
+		latitude:  51.5074,
+		longitude: -0.1278,
+		start:     "sunrise+30m",
+		stop:      "sunset-1h",
+		now:       "8:30 AM +0000",
+		result:    false,
+		latest:    expectedTime{isZero: true},
+	}),
+	Entry("after the offset from sunset", testCase{
+		location:  "Europe/London",
+		latitude:  51.5074,
+		longitude: -0.1278,
+		start:     "sunrise+30m",
+		stop:      "sunset-1h",
+		now:       "3:30 PM +0000",
+		result:    false,
+		latest:    expectedTime{isZero: true},
+	}),
+	Entry("on a day with a different sunrise", testCase{
+		location:  "Europe/London",
+		latitude:  51.5074,
+		longitude: -0.1278,
+		start:     "sunrise",
+		stop:      "sunset",
+		now:       "12:00 PM +0000",
+		nowDay:    time.Monday,
+		result:    true,
+		latest:    expectedTime{hour: 8, minute: 6, weekday: time.Monday},
+	}),
+	Entry("with an interval from the offset", testCase{
+		location:  "Europe/London",
+		latitude:  51.5074,
+		longitude: -0.1278,
+		start:     "sunrise+55m",
+		stop:      "sunset",
+		interval:  "1h",
+		prev:      "9:00 AM +0000",
+		now:       "10:10 AM +0000",
+		result:    true,
+		latest:    expectedTime{hour: 10},
+		list: []expectedTime{
+			{hour: 9},
+			{hour: 10},
+		},
+	}),
+	Entry("from sunset until a fixed time", testCase{
+		location:  "Europe/London",
+		latitude:  51.5074,
+		longitude: -0.1278,
+		start:     "sunset",
+		stop:      "11:00 PM",
+		now:       "8:00 PM +0000",
+		result:    true,
+		latest:    expectedTime{hour: 16, minute: 8},
+	}),
+	Entry("during polar night", testCase{
+		location:  "Europe/Oslo",
+		latitude:  69.6496,
+		longitude: 18.9560,
+		start:     "sunrise",
+		stop:      "sunset",
+		now:       "12:00 PM +0100",
+		result:    false,
+		latest:    expectedTime{isZero: true},
+	}),
+)
+
+var _ = DescribeTable("An aligned interval", (testCase).Run,
+	Entry("from the start of the window", testCase{
+		location:       "Europe/Berlin",
+		interval:       "25m",
+		intervalAnchor: models.INTERVAL_ANCHOR_WINDOW_START,
+		start:          "1:00 AM",
+		stop:           "11:00 PM",
+		prev:           "1:00 AM +0100",
+		now:            "2:00 AM +0100",
+		result:         true,
+		latest:         expectedTime{hour: 1, minute: 50},
+		list: []expectedTime{
+			{hour: 1},
+			{hour: 1, minute: 25},
+			{hour: 1, minute: 50},
+		},
+	}),
+	Entry("from local midnight", testCase{
+		location:       "Europe/Berlin",
+		interval:       "25m",
+		intervalAnchor: models.INTERVAL_ANCHOR_LOCAL_MIDNIGHT,
+		start:          "1:00 AM",
+		stop:           "11:00 PM",
+		prev:           "1:00 AM +0100",
+		now:            "2:00 AM +0100",
+		result:         true,
+		latest:         expectedTime{hour: 1, minute: 40},
+		list: []expectedTime{
+			{hour: 1, minute: 15},
+			{hour: 1, minute: 40},
+		},
+	}),
+	Entry("from the epoch", testCase{
+		location:       "Europe/Berlin",
+		interval:       "25m",
+		intervalAnchor: models.INTERVAL_ANCHOR_EPOCH,
+		start:          "1:00 AM",
+		stop:           "11:00 PM",
+		prev:           "1:00 AM +0100",
+		now:            "2:00 AM +0100",
+		result:         true,
+		latest:         expectedTime{hour: 1, minute: 45},
+		list: []expectedTime{
+			{hour: 1, minute: 20},
+			{hour: 1, minute: 45},
+		},
+	}),
+	Entry("before the next aligned time", testCase{
+		location:       "Europe/Berlin",
+		interval:       "25m",
+		intervalAnchor: models.INTERVAL_ANCHOR_LOCAL_MIDNIGHT,
+		start:          "1:00 AM",
+		stop:           "11:00 PM",
+		prev:           "1:15 AM +0100",
+		now:            "1:35 AM +0100",
+		result:         false,
+		latest:         expectedTime{hour: 1, minute: 15},
+	}),
+	Entry("a day at a time from local midnight", testCase{
+		location:       "Europe/Berlin",
+		interval:       "24h",
+		intervalAnchor: models.INTERVAL_ANCHOR_LOCAL_MIDNIGHT,
+		prev:           "12:00 AM +0100",
+		prevDay:        time.Saturday,
+		now:            "12:30 AM +0100",
+		result:         true,
+		latest:         expectedTime{hour: 0},
+		list: []expectedTime{
+			{hour: 0, weekday: time.Saturday},
+			{hour: 0},
+		},
+	}),
+	Entry("a day at a time from the epoch", testCase{
+		location:       "Europe/Berlin",
+		interval:       "24h",
+		intervalAnchor: models.INTERVAL_ANCHOR_EPOCH,
+		prev:           "12:00 AM +0100",
+		prevDay:        time.Saturday,
+		now:            "12:30 AM +0100",
+		result:         true,
+		latest:         expectedTime{hour: 0},
+		list: []expectedTime{
+			{hour: 0, weekday: time.Saturday},
+			{hour: 0},
+		},
+	}),
+)
+
+var _ = DescribeTable("Check decisions", (testCase).Run,
+	Entry("without a previous version", testCase{
+		start:  "9:00 AM +0000",
+		stop:   "5:00 PM +0000",
+		now:    "10:00 AM +0000",
+		result: true,
+		reason: lord.NO_PREVIOUS_VERSION,
+		latest: expectedTime{hour: 9},
+	}),
+	Entry("on a day that is not matched", testCase{
+		start:  "9:00 AM +0000",
+		stop:   "5:00 PM +0000",
+		days:   []time.Weekday{time.Monday},
+		now:    "10:00 AM +0000",
+		result: false,
+		reason: lord.DAY_NOT_MATCHED,
+		latest: expectedTime{isZero: true},
+	}),
+	Entry("during a blackout", testCase{
+		start:     "9:00 AM +0000",
+		stop:      "5:00 PM +0000",
+		blackouts: []testBlackout{{start: "9:30 AM +0000", stop: "10:30 AM +0000"}},
+		now:       "10:00 AM +0000",
+		result:    false,
+		reason:    lord.NOT_ALLOWED,
+		latest:    expectedTime{hour: 9},
+	}),
+	Entry("before start_after", testCase{
+		start:       "9:00 AM +0000",
+		stop:        "5:00 PM +0000",
+		start_after: "2018-01-08T00:00:00",
+		now:         "10:00 AM +0000",
+		result:      false,
+		reason:      lord.BEFORE_START_AFTER,
+		latest:      expectedTime{hour: 9},
+		list:        []expectedTime{},
+	}),
+	Entry("after stop_after", testCase{
+		start:      "9:00 AM +0000",
+		stop:       "5:00 PM +0000",
+		stop_after: "2018-01-07T09:30:00",
+		now:        "10:00 AM +0000",
+		result:     false,
+		reason:     lord.AFTER_STOP_AFTER,
+		latest:     expectedTime{hour: 9},
+	}),
+	Entry("outside the window", testCase{
+		start:  "9:00 AM +0000",
+		stop:   "5:00 PM +0000",
+		now:    "8:00 AM +0000",
+		result: false,
+		reason: lord.OUTSIDE_WINDOW,
+		latest: expectedTime{isZero: true},
+	}),
+	Entry("after the window started", testCase{
+		start:   "9:00 AM +0000",
+		stop:    "5:00 PM +0000",
+		prev:    "9:00 AM +0000",
+		prevDay: time.Saturday,
+		now:     "10:00 AM +0000",
+		result:  true,
+		reason:  lord.WINDOW_STARTED,
+		latest:  expectedTime{hour: 9},
+		list: []expectedTime{
+			{hour: 9, weekday: time.Saturday},
+			{hour: 9},
+		},
+	}),
+	Entry("after firing in the window", testCase{
+		start:  "9:00 AM +0000",
+		stop:   "5:00 PM +0000",
+		prev:   "9:30 AM +0000",
+		now:    "10:00 AM +0000",
+		result: false,
+		reason: lord.ALREADY_FIRED,
+		latest: expectedTime{isZero: true},
+	}),
+	Entry("after the interval elapsed", testCase{
+		interval: "1h",
+		prev:     "9:00 AM +0000",
+		now:      "10:05 AM +0000",
+		result:   true,
+		reason:   lord.INTERVAL_ELAPSED,
+		latest:   expectedTime{hour: 10},
+		list: []expectedTime{
+			{hour: 9},
+			{hour: 10},
+		},
+	}),
+	Entry("before the interval elapsed", testCase{
+		interval: "1h",
+		prev:     "9:30 AM +0000",
+		now:      "10:20 AM +0000",
+		result:   false,
+		reason:   lord.INTERVAL_NOT_ELAPSED,
+		latest:   expectedTime{hour: 10},
+	}),
+	Entry("after a scheduled time of a cron expression", testCase{
+		cron:    "0 9 * * *",
+		prev:    "9:00 AM +0000",
+		prevDay: time.Saturday,
+		now:     "10:00 AM +0000",
+		result:  true,
+		reason:  lord.SCHEDULED_TIME,
+		latest:  expectedTime{hour: 9},
+		list: []expectedTime{
+			{hour: 9, weekday: time.Saturday},
+			{hour: 9},
+		},
+	}),
+)
+
+type dstCase struct {
+	location string
+	policy   models.DSTPolicy
+
+	start    string
+	stop     string
+	interval string
+
+	prev string
+	now  string
+
+	result bool
+	list   []string
+}
+
+func (tc dstCase) Run() {
+	loc, err := time.LoadLocation(tc.location)
+	Expect(err).NotTo(HaveOccurred())
+
+	tl := lord.TimeLord{
+		Location:  (*models.Location)(loc),
+		DSTPolicy: tc.policy,
+	}
+
+	start := timeOfDay(tc.start, exampleFormatWithoutTZ)
+	tl.Start = &start
+	stop := timeOfDay(tc.stop, exampleFormatWithoutTZ)
+	tl.Stop = &stop
+
+	if tc.interval != "" {
+		interval, err := time.ParseDuration(tc.interval)
+		Expect(err).NotTo(HaveOccurred())
+
+		tl.Interval = (*models.Interval)(&interval)
+	}
+
+	if tc.prev != "" {
+		tl.PreviousTime, err = time.Parse(time.RFC3339, tc.prev)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	now, err := time.Parse(time.RFC3339, tc.now)
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(tl.Check(now).Fire).To(Equal(tc.result))
+
+	list := tl.List(now)
+	Expect(list).To(HaveLen(len(tc.list)))
+	for idx, actual := range list {
+		expected, err := time.Parse(time.RFC3339, tc.list[idx])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(BeTemporally("==", expected))
+	}
+
+	latest := tl.Latest(now)
+	if len(list) == 0 {
+		Expect(latest.IsZero()).To(BeTrue())
+	} else {
+		Expect(latest).To(BeTemporally("==", list[len(list)-1]))
+	}
+}
+
+var _ = DescribeTable("DST policies", (dstCase).Run,
+	Entry("skip, with a start skipped in New York", dstCase{
+		location: "America/New_York",
+		policy:   models.DST_SKIP,
+		start:    "2:30 AM",
+		stop:     "4:00 AM",
+		now:      "2018-03-11T03:45:00-04:00",
+		result:   false,
+	}),
+	Entry("shift_forward, with a start skipped in New York", dstCase{
+		location: "America/New_York",
+		policy:   models.DST_SHIFT_FORWARD,
+		start:    "2:30 AM",
+		stop:     "4:00 AM",
+		now:      "2018-03-11T03:45:00-04:00",
+		result:   true,
+		list:     []string{"2018-03-11T03:30:00-04:00"},
+	}),
+	Entry("run_once, with a start skipped in New York", dstCase{
+		location: "America/New_York",
+		policy:   models.DST_RUN_ONCE,
+		start:    "2:30 AM",
+		stop:     "4:00 AM",
+		now:      "2018-03-11T03:45:00-04:00",
+		result:   true,
+		list:     []string{"2018-03-11T03:30:00-04:00"},
+	}),
+	Entry("skip, with a window skipped in Berlin", dstCase{
+		location: "Europe/Berlin",
+		policy:   models.DST_SKIP,
+		start:    "2:30 AM",
+		stop:     "2:45 AM",
+		now:      "2018-03-25T03:40:00+02:00",
+		result:   false,
+	}),
+	Entry("shift_forward, with a window skipped in Berlin", dstCase{
+		location: "Europe/Berlin",
+		policy:   models.DST_SHIFT_FORWARD,
+		start:    "2:30 AM",
+		stop:     "2:45 AM",
+		now:      "2018-03-25T03:40:00+02:00",
+		result:   true,
+		list:     []string{"2018-03-25T03:30:00+02:00"},
+	}),
+	Entry("run_twice, with a start repeated in Berlin", dstCase{
+		location: "Europe/Berlin",
+		policy:   models.DST_RUN_TWICE,
+		start:    "2:30 AM",
+		stop:     "4:00 AM",
+		prev:     "2018-10-28T02:30:00+02:00",
+		now:      "2018-10-28T02:45:00+01:00",
+		result:   true,
+		list: []string{
+			"2018-10-28T02:30:00+02:00",
+			"2018-10-28T02:30:00+01:00",
+		},
+	}),
+	Entry("run_twice, with a stop repeated in Berlin", dstCase{
+		location: "Europe/Berlin",
+		policy:   models.DST_RUN_TWICE,
+		start:    "10:00 PM",
+		stop:     "2:00 AM",
+		interval: "1h",
+		prev:     "2018-10-28T01:00:00+02:00",
+		now:      "2018-10-28T02:30:00+01:00",
+		result:   false,
+		list:     []string{"2018-10-28T01:00:00+02:00"},
+	}),
+	Entry("run_twice, with a window repeated in Berlin", dstCase{
+		location: "Europe/Berlin",
+		policy:   models.DST_RUN_TWICE,
+		start:    "2:30 AM",
+		stop:     "2:45 AM",
+		prev:     "2018-10-28T02:30:00+02:00",
+		now:      "2018-10-28T02:50:00+02:00",
+		result:   false,
+		list:     []string{"2018-10-28T02:30:00+02:00"},
+	}),
+	Entry("run_once, with a start repeated in Berlin", dstCase{
+		location: "Europe/Berlin",
+		policy:   models.DST_RUN_ONCE,
+		start:    "2:30 AM",
+		stop:     "4:00 AM",
+		prev:     "2018-10-28T02:30:00+02:00",
+		now:      "2018-10-28T02:45:00+01:00",
+		result:   false,
+		list:     []string{"2018-10-28T02:30:00+02:00"},
+	}),
+	Entry("shift_forward, before the second time a start is repeated in Berlin", dstCase{
+		location: "Europe/Berlin",
+		policy:   models.DST_SHIFT_FORWARD,
+		start:    "2:30 AM",
+		stop:     "4:00 AM",
+		now:      "2018-10-28T02:45:00+02:00",
+		result:   false,
+	}),
+	Entry("shift_forward, after the second time a start is repeated in Berlin", dstCase{
+		location: "Europe/Berlin",
+		policy:   models.DST_SHIFT_FORWARD,
+		start:    "2:30 AM",
+		stop:     "4:00 AM",
+		now:      "2018-10-28T02:45:00+01:00",
+		result:   true,
+		list:     []string{"2018-10-28T02:30:00+01:00"},
+	}),
+	Entry("no policy, with an interval in elapsed time over a repeated hour in New York", dstCase{
+		location: "America/New_York",
+		start:    "12:00 AM",
+		stop:     "4:00 AM",
+		interval: "1h",
+		prev:     "2018-11-04T01:00:00-04:00",
+		now:      "2018-11-04T01:30:00-05:00",
+		result:   true,
+		list: []string{
+			"2018-11-04T01:00:00-04:00",
+			"2018-11-04T01:00:00-05:00",
+		},
+	}),
+	Entry("run_twice, with an interval over a repeated hour in New York", dstCase{
+		location: "America/New_York",
+		policy:   models.DST_RUN_TWICE,
+		start:    "12:00 AM",
+		stop:     "4:00 AM",
+		interval: "1h",
+		prev:     "2018-11-04T01:00:00-04:00",
+		now:      "2018-11-04T01:30:00-05:00",
+		result:   true,
+		list: []string{
+			"2018-11-04T01:00:00-04:00",
+			"2018-11-04T01:00:00-05:00",
+		},
+	}),
+	Entry("run_once, with an interval over a repeated hour in New York", dstCase{
+		location: "America/New_York",
+		policy:   models.DST_RUN_ONCE,
+		start:    "12:00 AM",
+		stop:     "4:00 AM",
+		interval: "1h",
+		prev:     "2018-11-04T01:00:00-04:00",
+		now:      "2018-11-04T01:30:00-05:00",
+		result:   false,
+		list:     []string{"2018-11-04T01:00:00-04:00"},
+	}),
+	Entry("shift_forward, with an interval over a repeated hour in New York", dstCase{
+		location: "America/New_York",
+		policy:   models.DST_SHIFT_FORWARD,
+		start:    "12:00 AM",
+		stop:     "4:00 AM",
+		interval: "1h",
+		prev:     "2018-11-04T01:00:00-04:00",
+		now:      "2018-11-04T01:30:00-05:00",
+		result:   true,
+		list:     []string{"2018-11-04T01:00:00-05:00"},
+	}),
+	Entry("skip, with an interval over a skipped hour in Sydney", dstCase{
+		location: "Australia/Sydney",
+		policy:   models.DST_SKIP,
+		start:    "1:00 AM",
+		stop:     "6:00 AM",
+		interval: "90m",
+		prev:     "2018-10-07T01:00:00+10:00",
+		now:      "2018-10-07T03:45:00+11:00",
+		result:   false,
+		list:     []string{"2018-10-07T01:00:00+10:00"},
+	}),
+	Entry("shift_forward, with an interval over a skipped hour in Sydney", dstCase{
+		location: "Australia/Sydney",
+		policy:   models.DST_SHIFT_FORWARD,
+		start:    "1:00 AM",
+		stop:     "6:00 AM",
+		interval: "90m",
+		prev:     "2018-10-07T01:00:00+10:00",
+		now:      "2018-10-07T03:45:00+11:00",
+		result:   true,
+		list: []string{
+			"2018-10-07T01:00:00+10:00",
+			"2018-10-07T03:30:00+11:00",
+		},
+	}),
+	Entry("run_twice, with a start and interval repeated in Sydney", dstCase{
+		location: "Australia/Sydney",
+		policy:   models.DST_RUN_TWICE,
+		start:    "2:00 AM",
+		stop:     "4:00 AM",
+		interval: "30m",
+		prev:     "2018-04-01T02:30:00+11:00",
+		now:      "2018-04-01T02:15:00+10:00",
+		result:   true,
+		list: []string{
+			"2018-04-01T02:30:00+11:00",
+			"2018-04-01T02:00:00+10:00",
+		},
+	}),
+	Entry("run_once, with a start and interval repeated in Sydney", dstCase{
+		location: "Australia/Sydney",
+		policy:   models.DST_RUN_ONCE,
+		start:    "2:00 AM",
+		stop:     "4:00 AM",
+		interval: "30m",
+		prev:     "2018-04-01T02:30:00+11:00",
+		now:      "2018-04-01T02:15:00+10:00",
+		result:   false,
+		list:     []string{"2018-04-01T02:30:00+11:00"},
+	}),
+)
@@ -0,0 +1,106 @@
+package resource
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/concourse/time-resource/lord"
+	"github.com/concourse/time-resource/models"
+)
+
+// Triggers tell how a version came about, as shown in its metadata.
+const (
+	// the version's time is one at which check could have produced it
+	TRIGGER_SCHEDULED = "scheduled"
+	// the version's time is not one at which check could have produced it,
+	// e.g. a version pinned or created by hand
+	TRIGGER_MANUAL = "manual"
+	// the version was produced by a put
+	TRIGGER_PUT = "put"
+)
+
+// scheduledWithin is how long after a scheduled time check produces a version
+// for it when checking at Concourse's default interval.
+const scheduledWithin = time.Minute
+
+const metadataTimeFormat = "2006-01-02 15:04:05 MST"
+
+// versionMetadata describes versionTime in the source's location and where
+// it falls in the source's schedule. If trigger is empty, it is scheduled or
+// manual depending on whether check could have produced versionTime. The metadata is only informational, so if the schedule cannot be
+// loaded, the fields describing it are left out.
+func versionMetadata(source models.Source, versionTime time.Time, trigger string) models.Metadata {
+	loc := time.UTC
+	if source.Location != nil {
+		loc = (*time.Location)(source.Location)
+	}
+
+	inLoc := versionTime.In(loc)
+	year, week := inLoc.ISOWeek()
+
+	metadata := models.Metadata{
+		{Name: "time", Value: inLoc.Format(metadataTimeFormat)},
+		{Name: "utc", Value: versionTime.UTC().Format(time.RFC3339)},
+		{Name: "epoch", Value: strconv.FormatInt(versionTime.Unix(), 10)},
+		{Name: "weekday", Value: inLoc.Weekday().String()},
+		{Name: "iso_week", Value: fmt.Sprintf("%d-W%02d", year, week)},
+	}
+
+	tl, err := timeLord(source, loc)
+	if err != nil {
+		if trigger != "" {
+			metadata = append(metadata, models.MetadataField{Name: "trigger", Value: trigger})
+		}
+		return metadata
+	}
+
+	// with spread, the schedule is evaluated in the shifted frame, as check
+	// does, and shifted back
+	var spread time.Duration
+	if source.Spread {
+		spread = spreadOffset(tl, inLoc)
+	}
+	checkTime := inLoc.Add(-spread)
+
+	if trigger == "" {
+		trigger = TRIGGER_MANUAL
+		if isScheduled(source, tl, checkTime) {
+			trigger = TRIGGER_SCHEDULED
+		}
+	}
+	metadata = append(metadata, models.MetadataField{Name: "trigger", Value: trigger})
+
+	// without a configured window, the whole day is the range
+	windowed := source.Start != nil || len(source.Windows) > 0 || len(source.Schedule) > 0
+	if r := tl.Check(checkTime).Range; windowed && !r.Start.IsZero() {
+		metadata = append(metadata, models.MetadataField{
+			Name: "window",
+			Value: fmt.Sprintf("%s to %s",
+				r.Start.Add(spread).In(loc).Format(metadataTimeFormat),
+				r.Stop.Add(spread).In(loc).Format(metadataTimeFormat)),
+		})
+	}
+
+	return metadata
+}
+
+// isScheduled reports whether check could have produced a version at
+// checkTime, the version's time before any spread.
+func isScheduled(source models.Source, tl lord.TimeLord, checkTime time.Time) bool {
+	latest := tl.Latest(checkTime)
+
+	// the versions are the scheduled times themselves
+	if source.Aligned || source.Spread {
+		return !latest.IsZero() && latest.Equal(checkTime)
+	}
+
+	// a version is produced whenever an interval has elapsed since the
+	// last one, so their times drift from the interval's times
+	if decision := tl.Check(checkTime); decision.Fire && decision.Range.Interval != nil {
+		return true
+	}
+
+	// otherwise, one is produced by the first check after a scheduled time
+	return !latest.IsZero() && checkTime.Sub(latest) < scheduledWithin
+}
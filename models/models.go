@@ -4,8 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/concourse/time-resource/cron"
+	"github.com/concourse/time-resource/holidays"
+	"github.com/concourse/time-resource/rrule"
 )
 
 type Version struct {
@@ -23,7 +31,13 @@ type InResponse struct {
 }
 
 type OutRequest struct {
-	Source Source `json:"source"`
+	Source Source    `json:"source"`
+	Params OutParams `json:"params"`
+}
+
+type OutParams struct {
+	BlackoutCalendars []string `json:"blackout_calendars"`
+	AllowCalendars    []string `json:"allow_calendars"`
 }
 
 type OutResponse struct {
@@ -38,14 +52,51 @@ type CheckRequest struct {
 
 type CheckResponse []Version
 
+type PreviewRequest struct {
+	Source Source    `json:"source"`
+	After  time.Time `json:"after"`
+	Count  int       `json:"count"`
+}
+
+type PreviewResponse []time.Time
+
 type Source struct {
-	InitialVersion bool        `json:"initial_version"`
-	Interval       *Interval   `json:"interval"`
-	Start          *TimeOfDay  `json:"start"`
-	Stop           *TimeOfDay  `json:"stop"`
-	Days           []Weekday   `json:"days"`
-	Location       *Location   `json:"location"`
-	StartAfter     *StartAfter `json:"start_after"`
+	InitialVersion bool             `json:"initial_version"`
+	Interval       *Interval        `json:"interval"`
+	IntervalAnchor IntervalAnchor   `json:"interval_anchor"`
+	Start          *TimeOfDay       `json:"start"`
+	Stop           *TimeOfDay       `json:"stop"`
+	Windows        []Window         `json:"windows"`
+	Schedule       Schedule         `json:"schedule"`
+	Blackouts      []Blackout       `json:"blackouts"`
+	Days           []Weekday        `json:"days"`
+	DaysOfMonth    []int            `json:"days_of_month"`
+	Months         []Month          `json:"months"`
+	Weeks          []int            `json:"weeks"`
+	WeekParity     string           `json:"week_parity"`
+	ExcludeDates   []DateRange      `json:"exclude_dates"`
+	SkipHolidays   []HolidayCountry `json:"skip_holidays"`
+	Location       *Location        `json:"location"`
+	Latitude       *float64         `json:"latitude"`
+	Longitude      *float64         `json:"longitude"`
+	DSTPolicy      DSTPolicy        `json:"dst_policy"`
+	StartAfter     *StartAfter      `json:"start_after"`
+	StopAfter      *StopAfter       `json:"stop_after"`
+	EveryNDays     int              `json:"every_n_days"`
+	EveryNWeeks    int              `json:"every_n_weeks"`
+	Anchor         *Anchor          `json:"anchor"`
+	Cron           *Cron            `json:"cron"`
+	RRule          *RRule           `json:"rrule"`
+	CatchUp        bool             `json:"catch_up"`
+	MaxCatchUp     int              `json:"max_catch_up"`
+	Aligned        bool             `json:"aligned"`
+	Spread         bool             `json:"spread"`
+	Debug          bool             `json:"debug"`
+
+	BusinessDaysOfMonth BusinessDaysOfMonth `json:"business_day_of_month"`
+
+	BlackoutCalendars []string `json:"blackout_calendars"`
+	AllowCalendars    []string `json:"allow_calendars"`
 }
 
 func (source Source) Validate() error {
@@ -57,16 +108,297 @@ func (source Source) Validate() error {
 		return errors.New("must configure 'start' if 'stop' is set")
 	}
 
+	// Validate windows replace start and stop and each have both
+	if len(source.Windows) > 0 {
+		if source.Start != nil || source.Stop != nil {
+			return errors.New("cannot configure 'start' or 'stop' if 'windows' is set")
+		}
+		if source.Cron != nil || source.RRule != nil {
+			return errors.New("cannot configure 'windows' if 'cron' or 'rrule' is set")
+		}
+	}
+	for i, window := range source.Windows {
+		if window.Start == nil || window.Stop == nil {
+			return fmt.Errorf("must configure 'start' and 'stop' for window %d", i+1)
+		}
+		for _, day := range window.Days {
+			if ordinal := day.Ordinal(); ordinal < -5 || ordinal > 5 {
+				return fmt.Errorf("invalid day in window %d: %v", i+1, day)
+			}
+		}
+	}
+
+	// Validate schedule replaces start, stop and windows
+	if len(source.Schedule) > 0 {
+		if source.Start != nil || source.Stop != nil || len(source.Windows) > 0 {
+			return errors.New("cannot configure 'start', 'stop' or 'windows' if 'schedule' is set")
+		}
+		if source.Cron != nil || source.RRule != nil {
+			return errors.New("cannot configure 'schedule' if 'cron' or 'rrule' is set")
+		}
+	}
+	for _, day := range slices.Sorted(maps.Keys(source.Schedule)) {
+		daySchedule := source.Schedule[day]
+		if ordinal := day.Ordinal(); ordinal < -5 || ordinal > 5 {
+			return fmt.Errorf("invalid day in schedule: %v", day)
+		}
+		if (daySchedule.Start != nil) != (daySchedule.Stop != nil) {
+			return fmt.Errorf("must configure both 'start' and 'stop' or neither for %v in schedule", day)
+		}
+	}
+
+	// Validate blackouts if specified
+	for i, blackout := range source.Blackouts {
+		if (blackout.Start != nil) != (blackout.Stop != nil) {
+			return fmt.Errorf("must configure both 'start' and 'stop' or neither for blackout %d", i+1)
+		}
+		for _, day := range blackout.Days {
+			if ordinal := day.Ordinal(); ordinal < -5 || ordinal > 5 {
+				return fmt.Errorf("invalid day in blackout %d: %v", i+1, day)
+			}
+		}
+		for _, dates := range blackout.Dates {
+			if dates.To.Before(dates.From) {
+				return fmt.Errorf("invalid dates range in blackout %d: %s ends before it starts", i+1, dates.From.Format(time.DateOnly))
+			}
+		}
+	}
+
+	// Validate times relative to the sun have a position to compute it for
+	if source.hasSolarTimes() && (source.Latitude == nil || source.Longitude == nil) {
+		return errors.New("must configure 'latitude' and 'longitude' if a time is relative to sunrise or sunset")
+	}
+	if source.Latitude != nil && (*source.Latitude < -90 || *source.Latitude > 90) {
+		return fmt.Errorf("invalid latitude: %v", *source.Latitude)
+	}
+	if source.Longitude != nil && (*source.Longitude < -180 || *source.Longitude > 180) {
+		return fmt.Errorf("invalid longitude: %v", *source.Longitude)
+	}
+
+	// Validate cron is not combined with the other scheduling options
+	if source.Cron != nil && (source.Interval != nil || source.Start != nil || source.Stop != nil) {
+		return errors.New("cannot configure 'interval', 'start' or 'stop' if 'cron' is set")
+	}
+
+	// Validate rrule is anchored and not combined with the other scheduling options
+	if source.RRule != nil {
+		if source.Cron != nil || source.Interval != nil || source.Start != nil || source.Stop != nil {
+			return errors.New("cannot configure 'interval', 'start', 'stop' or 'cron' if 'rrule' is set")
+		}
+		if source.StartAfter == nil {
+			return errors.New("must configure 'start_after' if 'rrule' is set")
+		}
+	}
+
+	// Validate dst_policy if specified, which cron and rrule handle their own way
+	switch source.DSTPolicy {
+	case "", DST_SKIP, DST_SHIFT_FORWARD, DST_RUN_TWICE, DST_RUN_ONCE:
+	default:
+		return fmt.Errorf("invalid dst_policy: %s, must be one of: skip, shift_forward, run_twice, run_once", source.DSTPolicy)
+	}
+	if source.DSTPolicy != "" && (source.Cron != nil || source.RRule != nil) {
+		return errors.New("cannot configure 'dst_policy' if 'cron' or 'rrule' is set")
+	}
+
+	// Validate interval_anchor if specified
+	switch source.IntervalAnchor {
+	case "", INTERVAL_ANCHOR_WINDOW_START, INTERVAL_ANCHOR_LOCAL_MIDNIGHT, INTERVAL_ANCHOR_EPOCH:
+	default:
+		return fmt.Errorf("invalid interval_anchor: %s, must be one of: window_start, local_midnight, epoch", source.IntervalAnchor)
+	}
+	if source.IntervalAnchor != "" && (source.Cron != nil || source.RRule != nil) {
+		return errors.New("cannot configure 'interval_anchor' if 'cron' or 'rrule' is set")
+	}
+	if source.IntervalAnchor != "" && !source.hasInterval() {
+		return errors.New("must configure 'interval' if 'interval_anchor' is set")
+	}
+
+	// Validate spread is only used with ranges and intervals
+	if source.Spread && (source.Cron != nil || source.RRule != nil) {
+		return errors.New("cannot configure 'spread' if 'cron' or 'rrule' is set")
+	}
+
+	// Validate stop_after does not end the schedule before it starts
+	if source.StartAfter != nil && source.StopAfter != nil && time.Time(*source.StopAfter).Before(time.Time(*source.StartAfter)) {
+		return errors.New("cannot configure 'stop_after' earlier than 'start_after'")
+	}
+
+	// Validate cadences are positive, exclusive and anchored
+	if source.EveryNDays < 0 {
+		return fmt.Errorf("invalid every_n_days: %d", source.EveryNDays)
+	}
+	if source.EveryNWeeks < 0 {
+		return fmt.Errorf("invalid every_n_weeks: %d", source.EveryNWeeks)
+	}
+	if source.EveryNDays != 0 && source.EveryNWeeks != 0 {
+		return errors.New("cannot configure both 'every_n_days' and 'every_n_weeks'")
+	}
+	if source.EveryNDays != 0 || source.EveryNWeeks != 0 {
+		if source.Anchor == nil && source.StartAfter == nil {
+			return errors.New("must configure 'anchor' or 'start_after' if 'every_n_days' or 'every_n_weeks' is set")
+		}
+	} else if source.Anchor != nil {
+		return errors.New("must configure 'every_n_days' or 'every_n_weeks' if 'anchor' is set")
+	}
+
+	// Validate max_catch_up only applies to catch_up
+	if source.MaxCatchUp != 0 {
+		if !source.CatchUp {
+			return errors.New("must configure 'catch_up' if 'max_catch_up' is set")
+		}
+		if source.MaxCatchUp < 0 {
+			return fmt.Errorf("invalid max_catch_up: %d", source.MaxCatchUp)
+		}
+	}
+
 	// Validate days if specified
 	for _, day := range source.Days {
-		if day < 0 || day > 6 {
+		if ordinal := day.Ordinal(); ordinal < -5 || ordinal > 5 {
 			return fmt.Errorf("invalid day: %v", day)
 		}
 	}
 
+	// Validate days of month if specified
+	for _, day := range source.DaysOfMonth {
+		if day == 0 || day < -31 || day > 31 {
+			return fmt.Errorf("invalid day of month: %d", day)
+		}
+	}
+
+	// Validate ISO weeks if specified
+	for _, week := range source.Weeks {
+		if week < 1 || week > 53 {
+			return fmt.Errorf("invalid week: %d", week)
+		}
+	}
+
+	// Validate week parity if specified
+	switch source.WeekParity {
+	case "", "even", "odd":
+	default:
+		return fmt.Errorf("invalid week_parity: %s, must be 'even' or 'odd'", source.WeekParity)
+	}
+
+	// Validate business days of month if specified
+	for _, day := range source.BusinessDaysOfMonth {
+		if day == 0 || day < -23 || day > 23 {
+			return fmt.Errorf("invalid business day of month: %d", day)
+		}
+	}
+
+	// Validate exclusion ranges if specified
+	for _, excluded := range source.ExcludeDates {
+		if excluded.To.Before(excluded.From) {
+			return fmt.Errorf("invalid exclude_dates range: %s ends before it starts", excluded.From.Format(time.DateOnly))
+		}
+	}
+
+	// Validate months if specified
+	for _, month := range source.Months {
+		if month < 1 || month > 12 {
+			return fmt.Errorf("invalid month: %v", month)
+		}
+	}
+
 	return nil
 }
 
+// BusinessDaysOfMonth selects business days by their position within the
+// month, where negative values count back from the end of the month.
+type BusinessDaysOfMonth []int
+
+// UnmarshalJSON accepts a single day (-1) or a list of days ([1, -1]).
+func (b *BusinessDaysOfMonth) UnmarshalJSON(payload []byte) error {
+	var days []int
+	err := json.Unmarshal(payload, &days)
+	if err != nil {
+		var day int
+		if json.Unmarshal(payload, &day) != nil {
+			return err
+		}
+		days = []int{day}
+	}
+
+	*b = days
+
+	return nil
+}
+
+// Window is a daily time range with its own optional days and interval. An
+// unset interval falls back to the source's interval.
+type Window struct {
+	Start    *TimeOfDay `json:"start"`
+	Stop     *TimeOfDay `json:"stop"`
+	Days     []Weekday  `json:"days"`
+	Interval *Interval  `json:"interval"`
+}
+
+// Schedule gives each weekday its own window. Days without an entry produce
+// no versions.
+type Schedule map[Weekday]DaySchedule
+
+// DaySchedule is the window for one day of a Schedule. As with the source's
+// start and stop, an unset start and stop cover the whole day, and an unset
+// interval falls back to the source's interval.
+type DaySchedule struct {
+	Start    *TimeOfDay `json:"start"`
+	Stop     *TimeOfDay `json:"stop"`
+	Interval *Interval  `json:"interval"`
+}
+
+// Blackout is a daily time range during which no versions are created,
+// optionally limited to certain days or dates. An unset start and stop cover
+// the whole day.
+type Blackout struct {
+	Start *TimeOfDay  `json:"start"`
+	Stop  *TimeOfDay  `json:"stop"`
+	Days  []Weekday   `json:"days"`
+	Dates []DateRange `json:"dates"`
+}
+
+// hasInterval reports whether an interval is set for the source, any of its
+// windows or any day of its schedule.
+func (source Source) hasInterval() bool {
+	if source.Interval != nil {
+		return true
+	}
+	for _, window := range source.Windows {
+		if window.Interval != nil {
+			return true
+		}
+	}
+	for _, daySchedule := range source.Schedule {
+		if daySchedule.Interval != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasSolarTimes reports whether any start or stop is relative to sunrise or
+// sunset.
+func (source Source) hasSolarTimes() bool {
+	times := []*TimeOfDay{source.Start, source.Stop}
+	for _, window := range source.Windows {
+		times = append(times, window.Start, window.Stop)
+	}
+	for _, daySchedule := range source.Schedule {
+		times = append(times, daySchedule.Start, daySchedule.Stop)
+	}
+	for _, blackout := range source.Blackouts {
+		times = append(times, blackout.Start, blackout.Stop)
+	}
+
+	for _, tod := range times {
+		if tod != nil && tod.Solar != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
 type Metadata []MetadataField
 
 type MetadataField struct {
@@ -135,10 +467,56 @@ func init() {
 	timeFormats = append(timeFormats, "1504")
 }
 
-type TimeOfDay time.Duration
+// IntervalAnchor is where the times of an interval are counted from, on the
+// clock in the source's location.
+type IntervalAnchor string
+
+const (
+	INTERVAL_ANCHOR_WINDOW_START   IntervalAnchor = "window_start"
+	INTERVAL_ANCHOR_LOCAL_MIDNIGHT IntervalAnchor = "local_midnight"
+	INTERVAL_ANCHOR_EPOCH          IntervalAnchor = "epoch"
+)
+
+// DSTPolicy decides what happens to a wall clock time that a DST transition
+// skips or repeats in the source's location.
+type DSTPolicy string
+
+const (
+	// skipped times do not happen, repeated times happen the first time
+	DST_SKIP DSTPolicy = "skip"
+	// skipped times are moved later by the length of the skipped time,
+	// repeated times happen the second time
+	DST_SHIFT_FORWARD DSTPolicy = "shift_forward"
+	// skipped times are moved later, repeated times happen both times
+	DST_RUN_TWICE DSTPolicy = "run_twice"
+	// skipped times are moved later, repeated times happen the first time
+	DST_RUN_ONCE DSTPolicy = "run_once"
+)
+
+// SolarEvent is a daily event of the sun, computed for the source's latitude
+// and longitude.
+type SolarEvent string
+
+const (
+	SUNRISE SolarEvent = "sunrise"
+	SUNSET  SolarEvent = "sunset"
+)
+
+var solarTimePattern = regexp.MustCompile(`(?i)^(sunrise|sunset)\s*(?:([+-])\s*(\S+))?$`)
+
+// TimeOfDay is a time of day, or an offset from sunrise or sunset if Solar
+// is set.
+type TimeOfDay struct {
+	Offset time.Duration
+	Solar  SolarEvent
+}
 
 func NewTimeOfDay(t time.Time) TimeOfDay {
-	return TimeOfDay(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+	return TimeOfDay{Offset: time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute}
+}
+
+func NewSolarTimeOfDay(event SolarEvent, offset time.Duration) TimeOfDay {
+	return TimeOfDay{Offset: offset, Solar: event}
 }
 
 func (tod *TimeOfDay) UnmarshalJSON(payload []byte) error {
@@ -148,6 +526,23 @@ func (tod *TimeOfDay) UnmarshalJSON(payload []byte) error {
 		return err
 	}
 
+	if match := solarTimePattern.FindStringSubmatch(strings.TrimSpace(timeStr)); match != nil {
+		var offset time.Duration
+		if match[3] != "" {
+			offset, err = time.ParseDuration(match[3])
+			if err != nil {
+				return fmt.Errorf("invalid offset from %s: %s", strings.ToLower(match[1]), match[3])
+			}
+			if match[2] == "-" {
+				offset = -offset
+			}
+		}
+
+		*tod = NewSolarTimeOfDay(SolarEvent(strings.ToLower(match[1])), offset)
+
+		return nil
+	}
+
 	var t time.Time
 	for _, format := range timeFormats {
 		t, err = time.Parse(format, timeStr)
@@ -156,7 +551,7 @@ func (tod *TimeOfDay) UnmarshalJSON(payload []byte) error {
 		}
 	}
 	if err != nil {
-		return fmt.Errorf("invalid time format: %s, must be one of: %s", timeStr, strings.Join(timeFormats, ", "))
+		return fmt.Errorf("invalid time format: %s, must be one of: %s, or relative to sunrise or sunset, e.g. sunset-1h", timeStr, strings.Join(timeFormats, ", "))
 	}
 
 	*tod = NewTimeOfDay(t.UTC())
@@ -168,20 +563,77 @@ func (tod TimeOfDay) MarshalJSON() ([]byte, error) {
 	return json.Marshal(tod.String())
 }
 
+// Hour returns the hour of a time of day that is not relative to the sun.
 func (tod TimeOfDay) Hour() int {
-	return int(time.Duration(tod) / time.Hour)
+	return int(tod.Offset / time.Hour)
 }
 
+// Minute returns the minute of a time of day that is not relative to the
+// sun.
 func (tod TimeOfDay) Minute() int {
-	return int(time.Duration(tod) % time.Hour / time.Minute)
+	return int(tod.Offset % time.Hour / time.Minute)
 }
 
 func (tod TimeOfDay) String() string {
-	return fmt.Sprintf("%d:%02d", tod.Hour(), tod.Minute())
+	if tod.Solar == "" {
+		return fmt.Sprintf("%d:%02d", tod.Hour(), tod.Minute())
+	}
+
+	if tod.Offset == 0 {
+		return string(tod.Solar)
+	}
+
+	sign := "+"
+	offset := tod.Offset
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+
+	offsetStr := offset.String()
+	if strings.HasSuffix(offsetStr, "m0s") {
+		offsetStr = strings.TrimSuffix(offsetStr, "0s")
+	}
+	if strings.HasSuffix(offsetStr, "h0m") {
+		offsetStr = strings.TrimSuffix(offsetStr, "0m")
+	}
+
+	return string(tod.Solar) + sign + offsetStr
 }
 
+// Weekday is a day of the week, optionally restricted to the nth such day
+// of the month. The ordinal is packed alongside the weekday so that plain
+// conversions like Weekday(time.Monday) keep meaning "every Monday".
 type Weekday time.Weekday
 
+func NewNthWeekday(ordinal int, wd time.Weekday) Weekday {
+	return Weekday(ordinal*7 + int(wd))
+}
+
+// Day returns the day of the week.
+func (wd Weekday) Day() time.Weekday {
+	return time.Weekday((int(wd)%7 + 7) % 7)
+}
+
+// Ordinal returns which occurrence of Day within the month is meant: 1 for
+// the first, -1 for the last, and 0 for every occurrence.
+func (wd Weekday) Ordinal() int {
+	return (int(wd) - int(wd.Day())) / 7
+}
+
+func (wd Weekday) String() string {
+	switch ordinal := wd.Ordinal(); {
+	case ordinal == 0:
+		return wd.Day().String()
+	case ordinal == -1:
+		return "last " + wd.Day().String()
+	case ordinal < 0:
+		return fmt.Sprintf("%d %s", ordinal, wd.Day())
+	default:
+		return ordinalString(ordinal) + " " + wd.Day().String()
+	}
+}
+
 func ParseWeekday(wdStr string) (time.Weekday, error) {
 	switch strings.ToLower(wdStr) {
 	case "sun", "sunday":
@@ -203,6 +655,78 @@ func ParseWeekday(wdStr string) (time.Weekday, error) {
 	return 0, fmt.Errorf("unknown weekday: %s", wdStr)
 }
 
+var ordinalWords = map[string]int{
+	"first":  1,
+	"second": 2,
+	"third":  3,
+	"fourth": 4,
+	"fifth":  5,
+	"last":   -1,
+}
+
+// ParseNthWeekday parses a weekday optionally preceded by an ordinal, e.g.
+// "Tuesday", "2nd Tuesday", "second Tuesday", "last Friday" or "-2 Monday".
+func ParseNthWeekday(wdStr string) (Weekday, error) {
+	fields := strings.Fields(wdStr)
+	if len(fields) == 1 {
+		wd, err := ParseWeekday(fields[0])
+		return Weekday(wd), err
+	}
+
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("unknown weekday: %s", wdStr)
+	}
+
+	ordinal, err := parseOrdinal(fields[0])
+	if err != nil {
+		return 0, fmt.Errorf("unknown weekday: %s", wdStr)
+	}
+
+	wd, err := ParseWeekday(fields[1])
+	if err != nil {
+		return 0, err
+	}
+
+	return NewNthWeekday(ordinal, wd), nil
+}
+
+func parseOrdinal(ordinalStr string) (int, error) {
+	ordinalStr = strings.ToLower(ordinalStr)
+	if ordinal, found := ordinalWords[ordinalStr]; found {
+		return ordinal, nil
+	}
+
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		if trimmed, found := strings.CutSuffix(ordinalStr, suffix); found {
+			ordinal, err := strconv.Atoi(trimmed)
+			if err != nil || ordinal < 1 || ordinal > 5 || ordinalString(ordinal) != ordinalStr {
+				return 0, fmt.Errorf("invalid ordinal: %s", ordinalStr)
+			}
+			return ordinal, nil
+		}
+	}
+
+	ordinal, err := strconv.Atoi(ordinalStr)
+	if err != nil || ordinal == 0 || ordinal < -5 || ordinal > 5 {
+		return 0, fmt.Errorf("invalid ordinal: %s", ordinalStr)
+	}
+
+	return ordinal, nil
+}
+
+func ordinalString(ordinal int) string {
+	switch ordinal {
+	case 1:
+		return "1st"
+	case 2:
+		return "2nd"
+	case 3:
+		return "3rd"
+	default:
+		return fmt.Sprintf("%dth", ordinal)
+	}
+}
+
 func (x *Weekday) UnmarshalJSON(payload []byte) error {
 	var wdStr string
 	err := json.Unmarshal(payload, &wdStr)
@@ -210,18 +734,79 @@ func (x *Weekday) UnmarshalJSON(payload []byte) error {
 		return err
 	}
 
-	wd, err := ParseWeekday(wdStr)
+	wd, err := ParseNthWeekday(wdStr)
 	if err != nil {
 		return err
 	}
 
-	*x = Weekday(wd)
+	*x = wd
 
 	return nil
 }
 
 func (wd Weekday) MarshalJSON() ([]byte, error) {
-	return json.Marshal(time.Weekday(wd).String())
+	return json.Marshal(wd.String())
+}
+
+// UnmarshalText allows weekdays to be used as JSON object keys.
+func (x *Weekday) UnmarshalText(text []byte) error {
+	wd, err := ParseNthWeekday(string(text))
+	if err != nil {
+		return err
+	}
+
+	*x = wd
+
+	return nil
+}
+
+func (wd Weekday) MarshalText() ([]byte, error) {
+	return []byte(wd.String()), nil
+}
+
+type Month time.Month
+
+func ParseMonth(monthStr string) (time.Month, error) {
+	if month, err := strconv.Atoi(monthStr); err == nil {
+		if month < 1 || month > 12 {
+			return 0, fmt.Errorf("unknown month: %s", monthStr)
+		}
+		return time.Month(month), nil
+	}
+
+	for month := time.January; month <= time.December; month++ {
+		name := strings.ToLower(month.String())
+		if strings.ToLower(monthStr) == name || strings.ToLower(monthStr) == name[:3] {
+			return month, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown month: %s", monthStr)
+}
+
+func (x *Month) UnmarshalJSON(payload []byte) error {
+	var monthStr string
+	err := json.Unmarshal(payload, &monthStr)
+	if err != nil {
+		var monthNum int
+		if json.Unmarshal(payload, &monthNum) != nil {
+			return err
+		}
+		monthStr = strconv.Itoa(monthNum)
+	}
+
+	month, err := ParseMonth(monthStr)
+	if err != nil {
+		return err
+	}
+
+	*x = Month(month)
+
+	return nil
+}
+
+func (m Month) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Month(m).String())
 }
 
 var dateTimeFormats = []string{
@@ -232,6 +817,19 @@ var dateTimeFormats = []string{
 	time.DateTime,
 }
 
+func parseDateTime(dateTimeStr string) (time.Time, error) {
+	var dateTime time.Time
+	var err error
+	for _, format := range dateTimeFormats {
+		dateTime, err = time.Parse(format, dateTimeStr)
+		if err == nil {
+			return dateTime, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date format: %s, must be one of: %s", dateTimeStr, strings.Join(dateTimeFormats, ", "))
+}
+
 type StartAfter time.Time
 
 func (sa *StartAfter) UnmarshalJSON(payload []byte) error {
@@ -242,16 +840,9 @@ func (sa *StartAfter) UnmarshalJSON(payload []byte) error {
 		return err
 	}
 
-	var startAfter time.Time
-	for _, format := range dateTimeFormats {
-		startAfter, err = time.Parse(format, dateTimeStr)
-		if err == nil {
-			break
-		}
-	}
-
+	startAfter, err := parseDateTime(dateTimeStr)
 	if err != nil {
-		return fmt.Errorf("invalid date format: %s, must be one of: %s", dateTimeStr, strings.Join(dateTimeFormats, ", "))
+		return err
 	}
 	*sa = StartAfter(startAfter)
 
@@ -262,3 +853,172 @@ func (sa StartAfter) MarshalJSON() ([]byte, error) {
 	StartAfterStr := time.Time(sa).Format("2006-01-02T15:04:05")
 	return json.Marshal(StartAfterStr)
 }
+
+// Anchor is the date from which every_n_days and every_n_weeks are counted.
+type Anchor time.Time
+
+func (a *Anchor) UnmarshalJSON(payload []byte) error {
+	var dateStr string
+
+	err := json.Unmarshal(payload, &dateStr)
+	if err != nil {
+		return err
+	}
+
+	anchor, err := parseDateTime(dateStr)
+	if err != nil {
+		return err
+	}
+	*a = Anchor(anchor)
+
+	return nil
+}
+
+func (a Anchor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(a).Format(time.DateOnly))
+}
+
+type StopAfter time.Time
+
+func (sa *StopAfter) UnmarshalJSON(payload []byte) error {
+	var dateTimeStr string
+
+	err := json.Unmarshal(payload, &dateTimeStr)
+	if err != nil {
+		return err
+	}
+
+	stopAfter, err := parseDateTime(dateTimeStr)
+	if err != nil {
+		return err
+	}
+	*sa = StopAfter(stopAfter)
+
+	return nil
+}
+
+func (sa StopAfter) MarshalJSON() ([]byte, error) {
+	StopAfterStr := time.Time(sa).Format("2006-01-02T15:04:05")
+	return json.Marshal(StopAfterStr)
+}
+
+// DateRange is an inclusive range of calendar days. Only the date part of
+// From and To is significant; it is compared against the date of a time in
+// whatever location that time is in.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Contains reports whether the calendar day of t falls within the range.
+func (dr DateRange) Contains(t time.Time) bool {
+	day := civilDay(t)
+	return day >= civilDay(dr.From) && day <= civilDay(dr.To)
+}
+
+func civilDay(t time.Time) int {
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
+
+// UnmarshalJSON accepts a single date ("2026-12-25") or a range of dates
+// separated by a slash ("2026-12-24/2026-12-26").
+func (dr *DateRange) UnmarshalJSON(payload []byte) error {
+	var rangeStr string
+	err := json.Unmarshal(payload, &rangeStr)
+	if err != nil {
+		return err
+	}
+
+	fromStr, toStr, isRange := strings.Cut(rangeStr, "/")
+
+	dr.From, err = parseDateTime(strings.TrimSpace(fromStr))
+	if err != nil {
+		return err
+	}
+
+	dr.To = dr.From
+	if isRange {
+		dr.To, err = parseDateTime(strings.TrimSpace(toStr))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (dr DateRange) MarshalJSON() ([]byte, error) {
+	if civilDay(dr.From) == civilDay(dr.To) {
+		return json.Marshal(dr.From.Format(time.DateOnly))
+	}
+	return json.Marshal(dr.From.Format(time.DateOnly) + "/" + dr.To.Format(time.DateOnly))
+}
+
+type Cron cron.Schedule
+
+func (c *Cron) UnmarshalJSON(payload []byte) error {
+	var cronStr string
+	err := json.Unmarshal(payload, &cronStr)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := cron.Parse(cronStr)
+	if err != nil {
+		return err
+	}
+
+	*c = Cron(*schedule)
+
+	return nil
+}
+
+func (c Cron) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*cron.Schedule)(&c).String())
+}
+
+type RRule rrule.Rule
+
+func (r *RRule) UnmarshalJSON(payload []byte) error {
+	var ruleStr string
+	err := json.Unmarshal(payload, &ruleStr)
+	if err != nil {
+		return err
+	}
+
+	rule, err := rrule.Parse(ruleStr)
+	if err != nil {
+		return err
+	}
+
+	*r = RRule(*rule)
+
+	return nil
+}
+
+func (r RRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*rrule.Rule)(&r).String())
+}
+
+type HolidayCountry holidays.Country
+
+func (hc *HolidayCountry) UnmarshalJSON(payload []byte) error {
+	var code string
+	err := json.Unmarshal(payload, &code)
+	if err != nil {
+		return err
+	}
+
+	country, err := holidays.Lookup(code)
+	if err != nil {
+		return err
+	}
+
+	*hc = HolidayCountry(*country)
+
+	return nil
+}
+
+func (hc HolidayCountry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hc.Code)
+}
@@ -2,6 +2,7 @@ package models_test
 
 import (
 	"encoding/json"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -73,4 +74,807 @@ var _ = Describe("Source", func() {
 			Expect(source.Stop.Hour()).To(Equal(source.Start.Hour() + 12))
 		})
 	})
+
+	Context("a cron expression", func() {
+		BeforeEach(func() {
+			config = `{ "cron": "0 10 * * SUN" }`
+		})
+
+		It("is valid", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(source.Cron).ToNot(BeNil())
+		})
+	})
+
+	Context("an invalid cron expression", func() {
+		BeforeEach(func() {
+			config = `{ "cron": "0 10 * *" }`
+		})
+
+		It("generates a parse error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("expected 5 or 6 fields but got 4"))
+		})
+	})
+
+	Context("a cron expression with an interval", func() {
+		BeforeEach(func() {
+			config = `{ "cron": "@hourly", "interval": "1h" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'interval', 'start' or 'stop' if 'cron' is set"))
+		})
+	})
+
+	Context("a cron expression with a range", func() {
+		BeforeEach(func() {
+			config = `{ "cron": "@hourly", "start": "3:04", "stop": "4:04" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'interval', 'start' or 'stop' if 'cron' is set"))
+		})
+	})
+
+	Context("a recurrence rule", func() {
+		BeforeEach(func() {
+			config = `{ "rrule": "FREQ=MONTHLY;BYDAY=2TU", "start_after": "2023-10-01T09:00:00" }`
+		})
+
+		It("is valid", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(source.RRule).ToNot(BeNil())
+		})
+	})
+
+	Context("a recurrence rule without start_after", func() {
+		BeforeEach(func() {
+			config = `{ "rrule": "FREQ=MONTHLY;BYDAY=2TU" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("must configure 'start_after' if 'rrule' is set"))
+		})
+	})
+
+	Context("a recurrence rule with an interval", func() {
+		BeforeEach(func() {
+			config = `{ "rrule": "FREQ=DAILY", "interval": "1h", "start_after": "2023-10-01" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'interval', 'start', 'stop' or 'cron' if 'rrule' is set"))
+		})
+	})
+
+	Context("a max_catch_up without catch_up", func() {
+		BeforeEach(func() {
+			config = `{ "interval": "1h", "max_catch_up": 5 }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("must configure 'catch_up' if 'max_catch_up' is set"))
+		})
+	})
+
+	Context("a negative max_catch_up", func() {
+		BeforeEach(func() {
+			config = `{ "interval": "1h", "catch_up": true, "max_catch_up": -1 }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid max_catch_up: -1"))
+		})
+	})
+
+	Context("a spread with a cron expression", func() {
+		BeforeEach(func() {
+			config = `{ "cron": "@hourly", "spread": true }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'spread' if 'cron' or 'rrule' is set"))
+		})
+	})
+
+	Context("days of month and months", func() {
+		BeforeEach(func() {
+			config = `{ "days_of_month": [1, 15, -1], "months": ["March", "jun", 9, "December"] }`
+		})
+
+		It("is valid", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(source.DaysOfMonth).To(Equal([]int{1, 15, -1}))
+			Expect(source.Months).To(Equal([]models.Month{
+				models.Month(time.March),
+				models.Month(time.June),
+				models.Month(time.September),
+				models.Month(time.December),
+			}))
+		})
+	})
+
+	Context("an unknown month", func() {
+		BeforeEach(func() {
+			config = `{ "months": ["Smarch"] }`
+		})
+
+		It("generates a parse error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("unknown month: Smarch"))
+		})
+	})
+
+	Context("an invalid day of month", func() {
+		BeforeEach(func() {
+			config = `{ "days_of_month": [0] }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid day of month: 0"))
+		})
+	})
+
+	Context("ordinal weekdays", func() {
+		BeforeEach(func() {
+			config = `{ "days": ["Monday", "2nd Tuesday", "second Wednesday", "last Friday", "-2 Saturday"] }`
+		})
+
+		It("is valid", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(source.Days).To(Equal([]models.Weekday{
+				models.Weekday(time.Monday),
+				models.NewNthWeekday(2, time.Tuesday),
+				models.NewNthWeekday(2, time.Wednesday),
+				models.NewNthWeekday(-1, time.Friday),
+				models.NewNthWeekday(-2, time.Saturday),
+			}))
+		})
+
+		It("round trips through JSON", func() {
+			payload, err := json.Marshal(source.Days)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(Equal(`["Monday","2nd Tuesday","2nd Wednesday","last Friday","-2 Saturday"]`))
+		})
+	})
+
+	Context("an invalid ordinal weekday", func() {
+		BeforeEach(func() {
+			config = `{ "days": ["6th Tuesday"] }`
+		})
+
+		It("generates a parse error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("unknown weekday: 6th Tuesday"))
+		})
+	})
+
+	Context("excluded dates and date ranges", func() {
+		BeforeEach(func() {
+			config = `{ "exclude_dates": ["2026-12-25", "2026-12-31/2027-01-01", "2026-07-04T00:00:00"] }`
+		})
+
+		It("is valid", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(source.ExcludeDates).To(HaveLen(3))
+			Expect(source.ExcludeDates[1].Contains(time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC))).To(BeTrue())
+			Expect(source.ExcludeDates[1].Contains(time.Date(2027, 1, 1, 1, 0, 0, 0, time.UTC))).To(BeTrue())
+			Expect(source.ExcludeDates[1].Contains(time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC))).To(BeFalse())
+		})
+
+		It("round trips through JSON", func() {
+			payload, err := json.Marshal(source.ExcludeDates)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(Equal(`["2026-12-25","2026-12-31/2027-01-01","2026-07-04"]`))
+		})
+	})
+
+	Context("an excluded date range that ends before it starts", func() {
+		BeforeEach(func() {
+			config = `{ "exclude_dates": ["2026-12-31/2026-12-24"] }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid exclude_dates range: 2026-12-31 ends before it starts"))
+		})
+	})
+
+	Context("an excluded date in an unknown format", func() {
+		BeforeEach(func() {
+			config = `{ "exclude_dates": ["Dec 25 2026"] }`
+		})
+
+		It("generates a parse error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("invalid date format: Dec 25 2026,"))
+		})
+	})
+
+	Context("holiday countries", func() {
+		BeforeEach(func() {
+			config = `{ "skip_holidays": ["US", "gb", "DE"] }`
+		})
+
+		It("parses and round trips through JSON", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+
+			payload, err := json.Marshal(source.SkipHolidays)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(Equal(`["US","GB","DE"]`))
+		})
+	})
+
+	Context("an unknown holiday country", func() {
+		BeforeEach(func() {
+			config = `{ "skip_holidays": ["Narnia"] }`
+		})
+
+		It("generates a parse error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("unknown holiday country: Narnia,"))
+		})
+	})
+
+	Context("windows", func() {
+		BeforeEach(func() {
+			config = `{ "interval": "15m", "windows": [
+				{ "start": "6:00", "stop": "9:00" },
+				{ "start": "17:00", "stop": "20:00", "days": ["Monday"], "interval": "1h" }
+			] }`
+		})
+
+		It("parses each window", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+
+			Expect(source.Windows).To(HaveLen(2))
+			Expect(source.Windows[0].Interval).To(BeNil())
+			Expect(source.Windows[1].Days).To(Equal([]models.Weekday{models.Weekday(time.Monday)}))
+			Expect(*source.Windows[1].Interval).To(Equal(models.Interval(time.Hour)))
+		})
+	})
+
+	Context("a window with no stop", func() {
+		BeforeEach(func() {
+			config = `{ "windows": [{ "start": "6:00", "stop": "9:00" }, { "start": "17:00" }] }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("must configure 'start' and 'stop' for window 2"))
+		})
+	})
+
+	Context("windows with a start and stop", func() {
+		BeforeEach(func() {
+			config = `{ "start": "6:00", "stop": "9:00", "windows": [{ "start": "17:00", "stop": "20:00" }] }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'start' or 'stop' if 'windows' is set"))
+		})
+	})
+
+	Context("windows with a cron expression", func() {
+		BeforeEach(func() {
+			config = `{ "cron": "0 * * * *", "windows": [{ "start": "17:00", "stop": "20:00" }] }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'windows' if 'cron' or 'rrule' is set"))
+		})
+	})
+
+	Context("a weekly schedule", func() {
+		BeforeEach(func() {
+			config = `{ "schedule": {
+				"Monday": { "start": "8:00", "stop": "18:00", "interval": "1h" },
+				"sat": { "start": "10:00", "stop": "14:00" }
+			} }`
+		})
+
+		It("parses each day", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+
+			Expect(source.Schedule).To(HaveLen(2))
+			Expect(source.Schedule).To(HaveKey(models.Weekday(time.Monday)))
+			Expect(source.Schedule).To(HaveKey(models.Weekday(time.Saturday)))
+			Expect(*source.Schedule[models.Weekday(time.Monday)].Interval).To(Equal(models.Interval(time.Hour)))
+			Expect(source.Schedule[models.Weekday(time.Saturday)].Interval).To(BeNil())
+		})
+
+		It("round trips through JSON", func() {
+			payload, err := json.Marshal(source.Schedule)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(ContainSubstring(`"Saturday":{"start":`))
+		})
+	})
+
+	Context("a schedule for an unknown day", func() {
+		BeforeEach(func() {
+			config = `{ "schedule": { "Caturday": {} } }`
+		})
+
+		It("generates a parse error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unknown weekday: Caturday"))
+		})
+	})
+
+	Context("a schedule day with a start and no stop", func() {
+		BeforeEach(func() {
+			config = `{ "schedule": { "Friday": { "start": "8:00" } } }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("must configure both 'start' and 'stop' or neither for Friday in schedule"))
+		})
+	})
+
+	Context("a schedule with a start and stop", func() {
+		BeforeEach(func() {
+			config = `{ "start": "8:00", "stop": "18:00", "schedule": { "Friday": {} } }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'start', 'stop' or 'windows' if 'schedule' is set"))
+		})
+	})
+
+	Context("blackouts", func() {
+		BeforeEach(func() {
+			config = `{ "interval": "10m", "blackouts": [
+				{ "start": "2:00", "stop": "3:00" },
+				{ "start": "12:00", "stop": "0:00", "days": ["Friday"] },
+				{ "dates": ["2026-12-24/2026-12-26"] }
+			] }`
+		})
+
+		It("parses each blackout", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+
+			Expect(source.Blackouts).To(HaveLen(3))
+			Expect(source.Blackouts[1].Days).To(Equal([]models.Weekday{models.Weekday(time.Friday)}))
+			Expect(source.Blackouts[2].Start).To(BeNil())
+			Expect(source.Blackouts[2].Dates).To(HaveLen(1))
+		})
+	})
+
+	Context("a blackout with a start and no stop", func() {
+		BeforeEach(func() {
+			config = `{ "blackouts": [{ "start": "2:00" }] }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("must configure both 'start' and 'stop' or neither for blackout 1"))
+		})
+	})
+
+	Context("a blackout with dates that end before they start", func() {
+		BeforeEach(func() {
+			config = `{ "blackouts": [{ "dates": ["2026-12-26/2026-12-24"] }] }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid dates range in blackout 1: 2026-12-26 ends before it starts"))
+		})
+	})
+
+	Context("a stop_after", func() {
+		BeforeEach(func() {
+			config = `{ "start_after": "2026-01-01", "stop_after": "2026-12-31T18:00" }`
+		})
+
+		It("parses and round trips through JSON", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+
+			Expect(time.Time(*source.StopAfter)).To(Equal(time.Date(2026, 12, 31, 18, 0, 0, 0, time.UTC)))
+
+			payload, err := json.Marshal(source.StopAfter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(Equal(`"2026-12-31T18:00:00"`))
+		})
+	})
+
+	Context("a stop_after earlier than start_after", func() {
+		BeforeEach(func() {
+			config = `{ "start_after": "2026-06-01", "stop_after": "2026-05-31" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'stop_after' earlier than 'start_after'"))
+		})
+	})
+
+	Context("an every_n_weeks cadence with an anchor", func() {
+		BeforeEach(func() {
+			config = `{ "every_n_weeks": 2, "days": ["Monday"], "anchor": "2026-01-05" }`
+		})
+
+		It("parses and round trips through JSON", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+
+			payload, err := json.Marshal(source.Anchor)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(Equal(`"2026-01-05"`))
+		})
+	})
+
+	Context("a cadence without an anchor or start_after", func() {
+		BeforeEach(func() {
+			config = `{ "every_n_days": 3 }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("must configure 'anchor' or 'start_after' if 'every_n_days' or 'every_n_weeks' is set"))
+		})
+	})
+
+	Context("both every_n_days and every_n_weeks", func() {
+		BeforeEach(func() {
+			config = `{ "every_n_days": 3, "every_n_weeks": 2, "anchor": "2026-01-05" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure both 'every_n_days' and 'every_n_weeks'"))
+		})
+	})
+
+	Context("a negative cadence", func() {
+		BeforeEach(func() {
+			config = `{ "every_n_days": -3, "anchor": "2026-01-05" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid every_n_days: -3"))
+		})
+	})
+
+	Context("an anchor without a cadence", func() {
+		BeforeEach(func() {
+			config = `{ "anchor": "2026-01-05" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("must configure 'every_n_days' or 'every_n_weeks' if 'anchor' is set"))
+		})
+	})
+
+	Context("weeks and week parity", func() {
+		BeforeEach(func() {
+			config = `{ "weeks": [1, 26, 53], "week_parity": "even" }`
+		})
+
+		It("parses and validates", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+
+			Expect(source.Weeks).To(Equal([]int{1, 26, 53}))
+			Expect(source.WeekParity).To(Equal("even"))
+		})
+	})
+
+	Context("an invalid week", func() {
+		BeforeEach(func() {
+			config = `{ "weeks": [54] }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid week: 54"))
+		})
+	})
+
+	Context("an invalid week parity", func() {
+		BeforeEach(func() {
+			config = `{ "week_parity": "every" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid week_parity: every, must be 'even' or 'odd'"))
+		})
+	})
+
+	Context("a single business day of month", func() {
+		BeforeEach(func() {
+			config = `{ "business_day_of_month": -1 }`
+		})
+
+		It("parses as a list", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+			Expect(source.BusinessDaysOfMonth).To(Equal(models.BusinessDaysOfMonth{-1}))
+		})
+	})
+
+	Context("several business days of month", func() {
+		BeforeEach(func() {
+			config = `{ "business_day_of_month": [1, 3, -1] }`
+		})
+
+		It("parses each day", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+			Expect(source.BusinessDaysOfMonth).To(Equal(models.BusinessDaysOfMonth{1, 3, -1}))
+		})
+	})
+
+	Context("an invalid business day of month", func() {
+		BeforeEach(func() {
+			config = `{ "business_day_of_month": [0] }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid business day of month: 0"))
+		})
+	})
+
+	Context("a start and stop relative to the sun", func() {
+		BeforeEach(func() {
+			config = `{ "start": "sunrise+30m", "stop": "Sunset - 1h", "latitude": 51.5074, "longitude": -0.1278 }`
+		})
+
+		It("parses and round trips through JSON", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+
+			Expect(*source.Start).To(Equal(models.NewSolarTimeOfDay(models.SUNRISE, 30*time.Minute)))
+			Expect(*source.Stop).To(Equal(models.NewSolarTimeOfDay(models.SUNSET, -time.Hour)))
+
+			payload, err := json.Marshal(source.Stop)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(Equal(`"sunset-1h"`))
+		})
+	})
+
+	Context("a time relative to the sun with an invalid offset", func() {
+		BeforeEach(func() {
+			config = `{ "start": "sunrise+soon", "stop": "sunset" }`
+		})
+
+		It("generates a parse error", func() {
+			Expect(err).To(MatchError("invalid offset from sunrise: soon"))
+		})
+	})
+
+	Context("a window relative to the sun without a latitude and longitude", func() {
+		BeforeEach(func() {
+			config = `{ "windows": [{ "start": "sunset", "stop": "23:00" }], "latitude": 51.5 }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("must configure 'latitude' and 'longitude' if a time is relative to sunrise or sunset"))
+		})
+	})
+
+	Context("an invalid latitude", func() {
+		BeforeEach(func() {
+			config = `{ "start": "sunrise", "stop": "sunset", "latitude": 91, "longitude": 0 }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid latitude: 91"))
+		})
+	})
+
+	Context("a dst_policy", func() {
+		BeforeEach(func() {
+			config = `{ "location": "Europe/Berlin", "start": "2:30", "stop": "4:00", "dst_policy": "run_twice" }`
+		})
+
+		It("parses", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+			Expect(source.DSTPolicy).To(Equal(models.DST_RUN_TWICE))
+		})
+	})
+
+	Context("an invalid dst_policy", func() {
+		BeforeEach(func() {
+			config = `{ "dst_policy": "ignore" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid dst_policy: ignore, must be one of: skip, shift_forward, run_twice, run_once"))
+		})
+	})
+
+	Context("a dst_policy with cron", func() {
+		BeforeEach(func() {
+			config = `{ "cron": "30 2 * * *", "dst_policy": "skip" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'dst_policy' if 'cron' or 'rrule' is set"))
+		})
+	})
+
+	Context("an invalid interval_anchor", func() {
+		BeforeEach(func() {
+			config = `{ "interval": "90m", "interval_anchor": "start" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid interval_anchor: start, must be one of: window_start, local_midnight, epoch"))
+		})
+	})
+
+	Context("an interval_anchor with rrule", func() {
+		BeforeEach(func() {
+			config = `{ "rrule": "FREQ=DAILY", "start_after": "2026-01-01", "interval_anchor": "epoch" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cannot configure 'interval_anchor' if 'cron' or 'rrule' is set"))
+		})
+	})
+
+	Context("an interval_anchor without an interval", func() {
+		BeforeEach(func() {
+			config = `{ "start": "9:00 AM", "stop": "5:00 PM", "interval_anchor": "window_start" }`
+		})
+
+		It("generates a validation error", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			err = source.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("must configure 'interval' if 'interval_anchor' is set"))
+		})
+	})
+
+	Context("an interval_anchor with an interval on a window", func() {
+		BeforeEach(func() {
+			config = `{ "windows": [{ "start": "9:00 AM", "stop": "5:00 PM", "interval": "90m" }], "interval_anchor": "window_start" }`
+		})
+
+		It("is valid", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source.Validate()).To(Succeed())
+		})
+	})
 })
@@ -18,7 +18,74 @@ const maxHashValue = int64(math.MaxUint32)
 
 var msPerMinute = time.Minute.Milliseconds()
 
+// Offset returns the time spread moves the latest scheduled time at or
+// before reference to, or reference if there is none.
 func Offset(tl lord.TimeLord, reference time.Time) time.Time {
+	scheduled := tl.Latest(reference)
+	if scheduled.IsZero() {
+		return reference
+	}
+
+	return scheduled.Add(rangeOffset(tl, scheduled))
+}
+
+// spreadOffset returns how much later spread shifts the schedule at
+// reference. It is the offset of the most recent range whose shifted start is
+// at or before reference, spread across the range's length on the wall clock,
+// or its interval if shorter, so that it is the same on days with DST
+// transitions.
+func spreadOffset(tl lord.TimeLord, reference time.Time) time.Duration {
+	// a range lasts at most a day, and is shifted by less than its length
+	earliest := reference.AddDate(0, 0, -2)
+
+	for r := range tl.RangesBefore(reference) {
+		if r.Start.Before(earliest) {
+			break
+		}
+
+		offset := hashOffset(nominalLength(tl, r))
+		if !r.Start.Add(offset).After(reference) {
+			return offset
+		}
+	}
+
+	return 0
+}
+
+// rangeOffset returns how much later spread shifts scheduled, a time of the
+// schedule before it is shifted.
+func rangeOffset(tl lord.TimeLord, scheduled time.Time) time.Duration {
+	for r := range tl.RangesBefore(scheduled) {
+		return hashOffset(nominalLength(tl, r))
+	}
+
+	return 0
+}
+
+// nominalLength returns the length of r on the wall clock in tl's location,
+// or of its interval if shorter.
+func nominalLength(tl lord.TimeLord, r lord.Range) time.Duration {
+	loc := time.UTC
+	if tl.Location != nil {
+		loc = (*time.Location)(tl.Location)
+	}
+
+	wallClock := func(t time.Time) time.Time {
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+
+	length := wallClock(r.Stop).Sub(wallClock(r.Start))
+	if r.Interval != nil && time.Duration(*r.Interval) < length {
+		length = time.Duration(*r.Interval)
+	}
+
+	return length
+}
+
+// hashOffset maps the team, pipeline and instance vars of the current build
+// to a whole number of minutes within rangeDuration.
+func hashOffset(rangeDuration time.Duration) time.Duration {
 	str := fmt.Sprintf(
 		"%s/%s/%s",
 		os.Getenv(BUILD_TEAM_NAME),
@@ -32,23 +99,13 @@ func Offset(tl lord.TimeLord, reference time.Time) time.Time {
 	}
 	hash := int64(hasher.Sum32())
 
-	start, stop := tl.LatestRangeBefore(reference)
-	rangeDuration := stop.Sub(start)
-
-	if tl.Interval != nil {
-		if intervalDuration := time.Duration(*tl.Interval); intervalDuration < rangeDuration {
-			rangeDuration = intervalDuration
-			start = reference.Truncate(rangeDuration)
-		}
-	}
-
 	if rangeDuration <= time.Minute {
-		return start
+		return 0
 	}
 
 	rangeMs := rangeDuration.Milliseconds()
 	if rangeMs <= 0 {
-		return start
+		return 0
 	}
 
 	minutesInRange := rangeMs / msPerMinute
@@ -73,6 +130,5 @@ func Offset(tl lord.TimeLord, reference time.Time) time.Time {
 		minutesToOffset = minutesInRange
 	}
 
-	offsetDuration := time.Duration(minutesToOffset) * time.Minute
-	return start.Add(offsetDuration)
+	return time.Duration(minutesToOffset) * time.Minute
 }
@@ -56,9 +56,8 @@ var _ = Describe("Offset", func() {
 		now time.Time
 		loc *time.Location
 
-		tl          lord.TimeLord
-		dayDuration time.Duration
-		reference   time.Time
+		tl        lord.TimeLord
+		reference time.Time
 
 		actualOffsetTime   time.Time
 		expectedOffsetTime time.Time
@@ -88,12 +87,21 @@ var _ = Describe("Offset", func() {
 		Expect(actualOffsetTime.Unix()).To(Equal(expectedOffsetTime.Unix()))
 	}
 
+	// the offset is spread across the length of a range on the wall clock,
+	// which differs from its duration across a DST change
+	wallClockLength := func(start, stop time.Time) time.Duration {
+		start, stop = start.In(loc), stop.In(loc)
+		return time.Date(stop.Year(), stop.Month(), stop.Day(), stop.Hour(), stop.Minute(), 0, 0, time.UTC).
+			Sub(time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC))
+	}
+
 	RunIntervalAndOrRangeTests := func() {
-		var rangeDuration time.Duration
+		var rangeDuration, offsetDuration time.Duration
 
 		Context("when a range is not specified", func() {
 			BeforeEach(func() {
-				rangeDuration = dayDuration
+				midnight := time.Date(reference.Year(), reference.Month(), reference.Day(), 0, 0, 0, 0, loc)
+				offsetDuration = wallClockLength(midnight, midnight.AddDate(0, 0, 1))
 
 				tl.Start = nil
 				tl.Stop = nil
@@ -105,7 +113,7 @@ var _ = Describe("Offset", func() {
 				})
 
 				JustBeforeEach(func() {
-					expectedOffsetTime = time.Date(reference.Year(), reference.Month(), reference.Day(), 0, 0, 0, 0, loc).Add(time.Duration(rangeDuration.Minutes()*env.hashPercentile) * time.Minute)
+					expectedOffsetTime = time.Date(reference.Year(), reference.Month(), reference.Day(), 0, 0, 0, 0, loc).Add(time.Duration(offsetDuration.Minutes()*env.hashPercentile) * time.Minute)
 				})
 
 				Context("when using a team name and pipeline name that generates a very small offset", func() {
@@ -191,7 +199,7 @@ var _ = Describe("Offset", func() {
 					})
 
 					JustBeforeEach(func() {
-						expectedOffsetTime = time.Date(reference.Year(), reference.Month(), reference.Day(), 0, 0, 0, 0, loc).Add(time.Duration(rangeDuration.Minutes()*env.hashPercentile) * time.Minute)
+						expectedOffsetTime = time.Date(reference.Year(), reference.Month(), reference.Day(), 0, 0, 0, 0, loc).Add(time.Duration(offsetDuration.Minutes()*env.hashPercentile) * time.Minute)
 					})
 
 					Context("when using a team name and pipeline name that generates a very small offset", func() {
@@ -226,6 +234,7 @@ var _ = Describe("Offset", func() {
 				*tl.Start = models.NewTimeOfDay(tlStart)
 
 				tlStop := tlStart.Add(rangeDuration)
+				offsetDuration = wallClockLength(tlStart, tlStop)
 				tl.Stop = new(models.TimeOfDay)
 				*tl.Stop = models.NewTimeOfDay(tlStop)
 			})
@@ -236,7 +245,7 @@ var _ = Describe("Offset", func() {
 				})
 
 				JustBeforeEach(func() {
-					expectedOffsetTime = reference.Truncate(rangeDuration).Add(time.Duration(rangeDuration.Minutes()*env.hashPercentile) * time.Minute)
+					expectedOffsetTime = reference.Truncate(rangeDuration).Add(time.Duration(offsetDuration.Minutes()*env.hashPercentile) * time.Minute)
 				})
 
 				Context("when using a team name and pipeline name that generates a very small offset", func() {
@@ -322,7 +331,7 @@ var _ = Describe("Offset", func() {
 					})
 
 					JustBeforeEach(func() {
-						expectedOffsetTime = reference.Truncate(rangeDuration).Add(time.Duration(rangeDuration.Minutes()*env.hashPercentile) * time.Minute)
+						expectedOffsetTime = reference.Truncate(rangeDuration).Add(time.Duration(offsetDuration.Minutes()*env.hashPercentile) * time.Minute)
 					})
 
 					Context("when using a team name and pipeline name that generates a very small offset", func() {
@@ -354,7 +363,6 @@ var _ = Describe("Offset", func() {
 			loc = time.UTC
 			tl.Location = nil
 			reference = time.Date(2012, time.April, 21, now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), loc)
-			dayDuration = 24 * time.Hour
 		})
 
 		RunIntervalAndOrRangeTests()
@@ -374,7 +382,6 @@ var _ = Describe("Offset", func() {
 		Context("when referencing a 23-hour day", func() {
 			BeforeEach(func() {
 				reference = time.Date(2012, time.March, 11, 6, now.Minute(), now.Second(), now.Nanosecond(), loc)
-				dayDuration = 23 * time.Hour
 			})
 
 			RunIntervalAndOrRangeTests()
@@ -383,7 +390,6 @@ var _ = Describe("Offset", func() {
 		Context("when referencing a 25-hour day", func() {
 			BeforeEach(func() {
 				reference = time.Date(2012, time.November, 4, 13, now.Minute(), now.Second(), now.Nanosecond(), loc)
-				dayDuration = 25 * time.Hour
 			})
 
 			RunIntervalAndOrRangeTests()
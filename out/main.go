@@ -10,6 +10,13 @@ import (
 )
 
 func main() {
+	if len(os.Args) < 2 {
+		println("usage: " + os.Args[0] + " <sources>")
+		os.Exit(1)
+	}
+
+	sourcesDir := os.Args[1]
+
 	var request models.OutRequest
 
 	err := json.NewDecoder(os.Stdin).Decode(&request)
@@ -18,9 +25,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	command := resource.OutCommand{}
+	clock, err := resource.ClockFromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error:", err.Error())
+		os.Exit(1)
+	}
+
+	command := resource.OutCommand{Clock: clock}
 
-	response, err := command.Run(request)
+	response, err := command.Run(sourcesDir, request)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "running command:", err.Error())
 		os.Exit(1)
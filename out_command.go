@@ -1,23 +1,49 @@
 package resource
 
 import (
+	"errors"
 	"time"
 
+	"github.com/concourse/time-resource/lord"
 	"github.com/concourse/time-resource/models"
 )
 
 type OutCommand struct {
+	// Clock tells the current time, defaulting to the system's clock
+	Clock Clock
 }
 
-func (*OutCommand) Run(request models.OutRequest) (models.OutResponse, error) {
-	currentTime := time.Now().UTC()
+func (command *OutCommand) Run(sourcesDir string, request models.OutRequest) (models.OutResponse, error) {
+	currentTime := currentTime(command.Clock).UTC()
 	specifiedLocation := request.Source.Location
 	if specifiedLocation != nil {
 		currentTime = currentTime.In((*time.Location)(specifiedLocation))
 	}
 
+	blackoutCalendars, err := loadCalendars(sourcesDir, request.Params.BlackoutCalendars, currentTime.Location())
+	if err != nil {
+		return models.OutResponse{}, err
+	}
+
+	allowCalendars, err := loadCalendars(sourcesDir, request.Params.AllowCalendars, currentTime.Location())
+	if err != nil {
+		return models.OutResponse{}, err
+	}
+
+	tl := lord.TimeLord{
+		Location:          specifiedLocation,
+		BlackoutCalendars: blackoutCalendars,
+		AllowCalendars:    allowCalendars,
+	}
+
+	if !tl.Check(currentTime).Fire {
+		return models.OutResponse{}, errors.New("current time is not allowed by the given calendars")
+	}
+
+	metadata := versionMetadata(request.Source, currentTime, TRIGGER_PUT)
+
 	outVersion := models.Version{Time: currentTime}
-	response := models.OutResponse{Version: outVersion}
+	response := models.OutResponse{Version: outVersion, Metadata: metadata}
 
 	return response, nil
 }
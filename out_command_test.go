@@ -2,6 +2,7 @@ package resource_test
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -18,7 +19,9 @@ var _ = Describe("Out", func() {
 		tmpdir string
 
 		source   models.Source
+		params   models.OutParams
 		response models.OutResponse
+		clock    resource.Clock
 
 		err error
 	)
@@ -30,12 +33,15 @@ var _ = Describe("Out", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		source = models.Source{}
+		params = models.OutParams{}
+		clock = nil
 	})
 
 	JustBeforeEach(func() {
-		command := resource.OutCommand{}
-		response, err = command.Run(models.OutRequest{
+		command := resource.OutCommand{Clock: clock}
+		response, err = command.Run(tmpdir, models.OutRequest{
 			Source: source,
+			Params: params,
 		})
 	})
 
@@ -75,5 +81,113 @@ var _ = Describe("Out", func() {
 				Expect(contained).To(BeTrue())
 			})
 		})
+
+		Context("when a clock is given", func() {
+			BeforeEach(func() {
+				clock = resource.FixedClock(time.Date(2018, 1, 8, 10, 30, 0, 0, time.UTC))
+
+				loc, err := time.LoadLocation("America/New_York")
+				Expect(err).ToNot(HaveOccurred())
+
+				srcLoc := models.Location(*loc)
+				source.Location = &srcLoc
+			})
+
+			It("reports the clock's time in the specified location as the version", func() {
+				Expect(response.Version.Time.Format(time.RFC3339)).To(Equal("2018-01-08T05:30:00-05:00"))
+			})
+
+			Context("when the source's calendars cannot be loaded", func() {
+				BeforeEach(func() {
+					source.BlackoutCalendars = []string{filepath.Join(tmpdir, "missing.ics")}
+				})
+
+				It("still reports the time as put in the metadata", func() {
+					Expect(response.Metadata).To(ContainElement(models.MetadataField{Name: "trigger", Value: "put"}))
+				})
+			})
+
+			It("reports the time as put in the metadata", func() {
+				Expect(response.Metadata).To(Equal(models.Metadata{
+					{Name: "time", Value: "2018-01-08 05:30:00 EST"},
+					{Name: "utc", Value: "2018-01-08T10:30:00Z"},
+					{Name: "epoch", Value: "1515407400"},
+					{Name: "weekday", Value: "Monday"},
+					{Name: "iso_week", Value: "2018-W02"},
+					{Name: "trigger", Value: "put"},
+				}))
+			})
+		})
+	})
+
+	Context("when calendars are given", func() {
+		writeCalendar := func(name string, start, end time.Time) {
+			content := "BEGIN:VCALENDAR\n" +
+				"BEGIN:VEVENT\n" +
+				"DTSTART:" + start.UTC().Format("20060102T150405Z") + "\n" +
+				"DTEND:" + end.UTC().Format("20060102T150405Z") + "\n" +
+				"END:VEVENT\n" +
+				"END:VCALENDAR\n"
+
+			err := os.WriteFile(filepath.Join(tmpdir, name), []byte(content), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Context("when the current time is within a blackout event", func() {
+			BeforeEach(func() {
+				writeCalendar("freeze.ics", now.Add(-time.Hour), now.Add(time.Hour))
+				params.BlackoutCalendars = []string{"freeze.ics"}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("current time is not allowed by the given calendars"))
+			})
+		})
+
+		Context("when the current time is outside every blackout event", func() {
+			BeforeEach(func() {
+				writeCalendar("freeze.ics", now.Add(time.Hour), now.Add(2*time.Hour))
+				params.BlackoutCalendars = []string{"freeze.ics"}
+			})
+
+			It("reports the current time as the version", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(response.Version.Time.Unix()).To(BeNumerically("~", now.Unix(), 1))
+			})
+		})
+
+		Context("when the current time is within an allow event", func() {
+			BeforeEach(func() {
+				writeCalendar("window.ics", now.Add(-time.Hour), now.Add(time.Hour))
+				params.AllowCalendars = []string{"window.ics"}
+			})
+
+			It("reports the current time as the version", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(response.Version.Time.Unix()).To(BeNumerically("~", now.Unix(), 1))
+			})
+		})
+
+		Context("when the current time is outside every allow event", func() {
+			BeforeEach(func() {
+				writeCalendar("window.ics", now.Add(time.Hour), now.Add(2*time.Hour))
+				params.AllowCalendars = []string{"window.ics"}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("current time is not allowed by the given calendars"))
+			})
+		})
+
+		Context("when a calendar does not exist", func() {
+			BeforeEach(func() {
+				params.BlackoutCalendars = []string{"missing.ics"}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("loading calendar " + filepath.Join(tmpdir, "missing.ics")))
+			})
+		})
 	})
 })
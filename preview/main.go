@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	resource "github.com/concourse/time-resource"
+	"github.com/concourse/time-resource/models"
+)
+
+const timeFormat = "Mon 2006-01-02 15:04:05 MST"
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: "+os.Args[0]+" [-n count] [-after time] [source.yml]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Lists the next times a source's schedule will fire. The source is read")
+		fmt.Fprintln(os.Stderr, "as JSON or YAML from the given file, or from stdin.")
+		fmt.Fprintln(os.Stderr)
+		flag.PrintDefaults()
+	}
+
+	count := flag.Int("n", resource.DEFAULT_PREVIEW_COUNT, "number of times to list")
+	after := flag.String("after", "", "list times after this RFC 3339 time instead of now")
+	flag.Parse()
+
+	var input io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		file, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "opening source:", err.Error())
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		input = file
+	}
+
+	source, err := resource.ReadSource(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error:", err.Error())
+		os.Exit(1)
+	}
+
+	request := models.PreviewRequest{Source: source, Count: *count}
+	if *after != "" {
+		request.After, err = time.Parse(time.RFC3339, *after)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "parse error:", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	clock, err := resource.ClockFromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error:", err.Error())
+		os.Exit(1)
+	}
+
+	command := resource.PreviewCommand{Clock: clock}
+
+	scheduled, err := command.Run(request)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "running command:", err.Error())
+		os.Exit(1)
+	}
+
+	loc := time.UTC
+	if source.Location != nil {
+		loc = (*time.Location)(source.Location)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(table, "UTC\t%s\n", loc)
+	for _, next := range scheduled {
+		fmt.Fprintf(table, "%s\t%s\n", next.UTC().Format(timeFormat), next.In(loc).Format(timeFormat))
+	}
+	table.Flush()
+}
@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"encoding/json"
+	"io"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/concourse/time-resource/lord"
+	"github.com/concourse/time-resource/models"
+)
+
+const DEFAULT_PREVIEW_COUNT = 10
+
+type PreviewCommand struct {
+	// Clock tells the current time, defaulting to the system's clock
+	Clock Clock
+}
+
+// Run returns the next times after request.After, or after now if it is not
+// set, at which check would produce versions. With spread, each time is
+// shifted later by the pipeline's offset, as check does.
+func (command *PreviewCommand) Run(request models.PreviewRequest) (models.PreviewResponse, error) {
+	err := request.Source.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	after := request.After
+	if after.IsZero() {
+		after = currentTime(command.Clock).UTC()
+	}
+
+	count := request.Count
+	if count == 0 {
+		count = DEFAULT_PREVIEW_COUNT
+	}
+
+	tl, err := timeLord(request.Source, after.Location())
+	if err != nil {
+		return nil, err
+	}
+
+	// with spread, times are listed in the shifted frame, as check does
+	from := after
+	if request.Source.Spread {
+		from = after.Add(-spreadOffset(tl, after))
+	}
+
+	scheduled := models.PreviewResponse{}
+	last := after
+	for searched := 0; len(scheduled) < count && searched <= lord.MAX_DAYS_SEARCHED; searched++ {
+		reference := from.AddDate(0, 0, 1)
+
+		tl.PreviousTime = from
+		for _, next := range tl.List(reference) {
+			if request.Source.Spread {
+				next = next.Add(rangeOffset(tl, next))
+			}
+
+			if next.After(last) && len(scheduled) < count {
+				scheduled = append(scheduled, next)
+				last = next
+			}
+		}
+
+		from = reference
+	}
+
+	return scheduled, nil
+}
+
+// ReadSource decodes a source configuration given as JSON or YAML, as it
+// appears under a resource's source in a pipeline.
+func ReadSource(r io.Reader) (models.Source, error) {
+	var config any
+	err := yaml.NewDecoder(r).Decode(&config)
+	if err != nil && err != io.EOF {
+		return models.Source{}, err
+	}
+
+	payload, err := json.Marshal(config)
+	if err != nil {
+		return models.Source{}, err
+	}
+
+	var source models.Source
+	err = json.Unmarshal(payload, &source)
+	if err != nil {
+		return models.Source{}, err
+	}
+
+	return source, nil
+}
@@ -0,0 +1,208 @@
+package resource_test
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	resource "github.com/concourse/time-resource"
+	"github.com/concourse/time-resource/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Preview", func() {
+	originalTeam := os.Getenv(resource.BUILD_TEAM_NAME)
+	originalPipeline := os.Getenv(resource.BUILD_PIPELINE_NAME)
+	originalPipelineInstanceVars := os.Getenv(resource.BUILD_PIPELINE_INSTANCE_VARS)
+
+	var (
+		config  string
+		request models.PreviewRequest
+
+		response models.PreviewResponse
+		err      error
+	)
+
+	BeforeEach(func() {
+		os.Setenv(resource.BUILD_TEAM_NAME, "")
+		os.Setenv(resource.BUILD_PIPELINE_NAME, "")
+		os.Setenv(resource.BUILD_PIPELINE_INSTANCE_VARS, "")
+
+		config = ""
+		request = models.PreviewRequest{
+			After: time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+			Count: 3,
+		}
+	})
+
+	JustBeforeEach(func() {
+		request.Source, err = resource.ReadSource(strings.NewReader(config))
+		Expect(err).NotTo(HaveOccurred())
+
+		command := resource.PreviewCommand{}
+		response, err = command.Run(request)
+	})
+
+	AfterEach(func() {
+		os.Setenv(resource.BUILD_TEAM_NAME, originalTeam)
+		os.Setenv(resource.BUILD_PIPELINE_NAME, originalPipeline)
+		os.Setenv(resource.BUILD_PIPELINE_INSTANCE_VARS, originalPipelineInstanceVars)
+	})
+
+	expectTimes := func(expected ...string) {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(HaveLen(len(expected)))
+		for i, e := range expected {
+			expectedTime, err := time.Parse(time.RFC3339, e)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response[i]).To(BeTemporally("==", expectedTime))
+		}
+	}
+
+	Context("with an interval within a range", func() {
+		BeforeEach(func() {
+			config = `
+start: 9:00 AM
+stop: 10:00 AM
+interval: 20m
+location: Europe/Berlin
+`
+		})
+
+		It("lists the next times of the interval", func() {
+			expectTimes(
+				"2026-10-17T09:00:00+02:00",
+				"2026-10-17T09:20:00+02:00",
+				"2026-10-17T09:40:00+02:00",
+			)
+		})
+
+		Context("when more times are asked for than fit in a day", func() {
+			BeforeEach(func() {
+				request.Count = 4
+			})
+
+			It("continues on the next day", func() {
+				expectTimes(
+					"2026-10-17T09:00:00+02:00",
+					"2026-10-17T09:20:00+02:00",
+					"2026-10-17T09:40:00+02:00",
+					"2026-10-18T09:00:00+02:00",
+				)
+			})
+		})
+
+		Context("when the pipeline's build environment is set", func() {
+			BeforeEach(func() {
+				os.Setenv(resource.BUILD_TEAM_NAME, smallOffset.teamName)
+				os.Setenv(resource.BUILD_PIPELINE_NAME, smallOffset.pipelineName)
+			})
+
+			It("lists the times without an offset", func() {
+				expectTimes(
+					"2026-10-17T09:00:00+02:00",
+					"2026-10-17T09:20:00+02:00",
+					"2026-10-17T09:40:00+02:00",
+				)
+			})
+
+			Context("when spread is specified", func() {
+				BeforeEach(func() {
+					config += "spread: true\n"
+				})
+
+				It("shifts each time by the pipeline's offset within the interval", func() {
+					// 47% of 20 minutes
+					expectTimes(
+						"2026-10-17T09:09:00+02:00",
+						"2026-10-17T09:29:00+02:00",
+						"2026-10-17T09:49:00+02:00",
+					)
+				})
+			})
+		})
+	})
+
+	Context("with an interval within a range starting off its grid", func() {
+		BeforeEach(func() {
+			config = `
+start: 9:05 AM
+stop: 1:00 PM
+interval: 1h
+`
+			request.Count = 5
+		})
+
+		It("lists the times counted from the start of the range, as check produces them", func() {
+			expectTimes(
+				"2026-10-17T09:05:00Z",
+				"2026-10-17T10:05:00Z",
+				"2026-10-17T11:05:00Z",
+				"2026-10-17T12:05:00Z",
+				"2026-10-18T09:05:00Z",
+			)
+		})
+	})
+
+	Context("with a range on certain days", func() {
+		BeforeEach(func() {
+			config = `{ "start": "6:00 PM", "stop": "7:00 PM", "days": ["Monday", "Thursday"] }`
+		})
+
+		It("lists the start of the range on each day, given as JSON", func() {
+			expectTimes(
+				"2026-10-19T18:00:00Z",
+				"2026-10-22T18:00:00Z",
+				"2026-10-26T18:00:00Z",
+			)
+		})
+	})
+
+	Context("with a cron expression", func() {
+		BeforeEach(func() {
+			config = `
+cron: "30 9 * * 1-5"
+location: America/New_York
+`
+			os.Setenv(resource.BUILD_TEAM_NAME, smallOffset.teamName)
+			os.Setenv(resource.BUILD_PIPELINE_NAME, smallOffset.pipelineName)
+		})
+
+		It("lists the next times it fires, without an offset", func() {
+			expectTimes(
+				"2026-10-19T09:30:00-04:00",
+				"2026-10-20T09:30:00-04:00",
+				"2026-10-21T09:30:00-04:00",
+			)
+		})
+	})
+
+	Context("with a schedule that has ended", func() {
+		BeforeEach(func() {
+			config = `
+interval: 1h
+stop_after: 2026-10-17T02:30:00
+`
+			request.Count = 0
+		})
+
+		It("lists the times that are left", func() {
+			expectTimes(
+				"2026-10-17T01:00:00Z",
+				"2026-10-17T02:00:00Z",
+			)
+		})
+	})
+
+	Context("with an invalid source", func() {
+		BeforeEach(func() {
+			config = `start: 9:00 AM`
+		})
+
+		It("returns the validation error", func() {
+			Expect(err).To(MatchError("must configure 'stop' if 'start' is set"))
+		})
+	})
+})
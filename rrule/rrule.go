@@ -0,0 +1,603 @@
+package rrule
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit bounds how far Next and Prev will look for an occurrence, so
+// that rules which can never match (e.g. "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30")
+// terminate.
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+type Frequency int
+
+const (
+	Minutely Frequency = iota
+	Hourly
+	Daily
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencies = map[string]Frequency{
+	"MINUTELY": Minutely,
+	"HOURLY":   Hourly,
+	"DAILY":    Daily,
+	"WEEKLY":   Weekly,
+	"MONTHLY":  Monthly,
+	"YEARLY":   Yearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var untilFormats = []string{
+	"20060102T150405Z",
+	"20060102T150405",
+	"20060102",
+}
+
+// WeekdayNum is an entry of BYDAY: a weekday with an optional ordinal, e.g.
+// "2TU" (the second Tuesday) or "-1FR" (the last Friday). An ordinal of 0
+// means every such weekday.
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed RFC 5545 recurrence rule. It needs a DTSTART, supplied
+// via Recurrence, before occurrences can be computed.
+type Rule struct {
+	spec string
+
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+	Count      int
+	WeekStart  time.Weekday
+
+	until      time.Time
+	untilLocal bool
+}
+
+// Parse parses an RRULE value such as "FREQ=MONTHLY;BYDAY=2TU". An optional
+// "RRULE:" prefix is accepted.
+func Parse(spec string) (*Rule, error) {
+	rule := &Rule{
+		spec:      strings.TrimSpace(spec),
+		Interval:  1,
+		WeekStart: time.Monday,
+	}
+
+	value := strings.TrimPrefix(rule.spec, "RRULE:")
+
+	hasFreq := false
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+
+		name, val, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid rrule part: %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			var known bool
+			rule.Freq, known = frequencies[strings.ToUpper(val)]
+			if !known {
+				return nil, fmt.Errorf("unsupported rrule FREQ: %s", val)
+			}
+			hasFreq = true
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+			if err == nil && rule.Interval < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err == nil && rule.Count < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "UNTIL":
+			err = rule.parseUntil(val)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseInts(val, -31, 31)
+		case "BYMONTH":
+			rule.ByMonth, err = parseInts(val, 1, 12)
+		case "BYSETPOS":
+			rule.BySetPos, err = parseInts(val, -366, 366)
+		case "WKST":
+			var known bool
+			rule.WeekStart, known = weekdays[strings.ToUpper(val)]
+			if !known {
+				err = fmt.Errorf("unknown weekday")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rrule part: %s", name)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid rrule %s: %q: %w", strings.ToUpper(name), val, err)
+		}
+	}
+
+	if !hasFreq {
+		return nil, fmt.Errorf("invalid rrule: %q, FREQ is required", spec)
+	}
+
+	if rule.Count != 0 && !rule.until.IsZero() {
+		return nil, fmt.Errorf("invalid rrule: %q, COUNT and UNTIL are mutually exclusive", spec)
+	}
+
+	for _, wd := range rule.ByDay {
+		if wd.Ordinal != 0 && rule.Freq != Monthly && rule.Freq != Yearly {
+			return nil, fmt.Errorf("invalid rrule: %q, BYDAY ordinals are only allowed with FREQ=MONTHLY or FREQ=YEARLY", spec)
+		}
+	}
+
+	return rule, nil
+}
+
+func (r *Rule) parseUntil(val string) error {
+	var err error
+	for _, format := range untilFormats {
+		r.until, err = time.Parse(format, val)
+		if err == nil {
+			r.untilLocal = !strings.HasSuffix(format, "Z")
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of: %s", strings.Join(untilFormats, ", "))
+}
+
+func parseByDay(val string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.ToUpper(strings.TrimSpace(entry))
+		if len(entry) < 2 {
+			return nil, fmt.Errorf("unknown weekday: %s", entry)
+		}
+
+		wd, known := weekdays[entry[len(entry)-2:]]
+		if !known {
+			return nil, fmt.Errorf("unknown weekday: %s", entry)
+		}
+
+		ordinal := 0
+		if prefix := entry[:len(entry)-2]; prefix != "" {
+			var err error
+			ordinal, err = strconv.Atoi(prefix)
+			if err != nil || ordinal == 0 || ordinal < -53 || ordinal > 53 {
+				return nil, fmt.Errorf("invalid ordinal: %s", entry)
+			}
+		}
+
+		days = append(days, WeekdayNum{Ordinal: ordinal, Weekday: wd})
+	}
+	return days, nil
+}
+
+func parseInts(val string, min, max int) ([]int, error) {
+	var values []int
+	for _, entry := range strings.Split(val, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(entry))
+		if err != nil || v == 0 || v < min || v > max {
+			return nil, fmt.Errorf("invalid value: %s", entry)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (r *Rule) String() string {
+	return r.spec
+}
+
+// Recurrence anchors the rule on dtstart. The time of day and location of
+// every occurrence are taken from dtstart.
+func (r *Rule) Recurrence(dtstart time.Time) *Recurrence {
+	until := r.until
+	if r.untilLocal {
+		until = time.Date(until.Year(), until.Month(), until.Day(),
+			until.Hour(), until.Minute(), until.Second(), 0, dtstart.Location())
+		if r.until.Hour() == 0 && r.until.Minute() == 0 && r.until.Second() == 0 {
+			// a date-only UNTIL includes the whole day
+			until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+
+	return &Recurrence{
+		rule:    r,
+		dtstart: dtstart,
+		until:   until,
+	}
+}
+
+// Recurrence is a Rule anchored on a DTSTART.
+type Recurrence struct {
+	rule    *Rule
+	dtstart time.Time
+	until   time.Time
+}
+
+// Next returns the earliest occurrence strictly after t, or the zero time
+// if there is none.
+func (rec *Recurrence) Next(t time.Time) time.Time {
+	if rec.rule.Count != 0 {
+		var next time.Time
+		rec.enumerate(func(occurrence time.Time) bool {
+			if occurrence.After(t) {
+				next = occurrence
+				return false
+			}
+			return true
+		})
+		return next
+	}
+
+	limit := t.Add(searchLimit)
+	for k := max(rec.periodIndex(t), 0); ; k++ {
+		occurrences := rec.period(k)
+		if len(occurrences) == 0 && rec.periodStart(k).After(limit) {
+			return time.Time{}
+		}
+
+		for _, occurrence := range occurrences {
+			if rec.pastUntil(occurrence) {
+				return time.Time{}
+			}
+			if occurrence.After(t) {
+				return occurrence
+			}
+		}
+	}
+}
+
+// Prev returns the latest occurrence at or before t, or the zero time if
+// there is none.
+func (rec *Recurrence) Prev(t time.Time) time.Time {
+	if t.Before(rec.dtstart) {
+		return time.Time{}
+	}
+
+	if rec.rule.Count != 0 {
+		var prev time.Time
+		rec.enumerate(func(occurrence time.Time) bool {
+			if occurrence.After(t) {
+				return false
+			}
+			prev = occurrence
+			return true
+		})
+		return prev
+	}
+
+	limit := t.Add(-searchLimit)
+	for k := rec.periodIndex(t); k >= 0; k-- {
+		occurrences := rec.period(k)
+		for i := len(occurrences) - 1; i >= 0; i-- {
+			if !occurrences[i].After(t) && !rec.pastUntil(occurrences[i]) {
+				return occurrences[i]
+			}
+		}
+
+		if rec.periodStart(k).Before(limit) {
+			break
+		}
+	}
+	return time.Time{}
+}
+
+// enumerate calls fn with each occurrence in order, counting from dtstart,
+// until fn returns false or the rule is exhausted.
+func (rec *Recurrence) enumerate(fn func(time.Time) bool) {
+	count := 0
+	for k := 0; ; k++ {
+		occurrences := rec.period(k)
+		if len(occurrences) == 0 && rec.periodStart(k).Sub(rec.dtstart) > searchLimit && count == 0 {
+			return
+		}
+
+		for _, occurrence := range occurrences {
+			if rec.pastUntil(occurrence) || !fn(occurrence) {
+				return
+			}
+			count++
+			if rec.rule.Count != 0 && count >= rec.rule.Count {
+				return
+			}
+		}
+	}
+}
+
+func (rec *Recurrence) pastUntil(occurrence time.Time) bool {
+	return !rec.until.IsZero() && occurrence.After(rec.until)
+}
+
+// periodIndex returns the index of the period containing t, counting from
+// the period containing dtstart.
+func (rec *Recurrence) periodIndex(t time.Time) int {
+	t = t.In(rec.dtstart.Location())
+	interval := rec.rule.Interval
+
+	switch rec.rule.Freq {
+	case Yearly:
+		return floorDiv(t.Year()-rec.dtstart.Year(), interval)
+	case Monthly:
+		return floorDiv(monthIndex(t)-monthIndex(rec.dtstart), interval)
+	case Weekly:
+		return floorDiv(daysBetween(rec.weekStart(rec.dtstart), t), 7*interval)
+	case Daily:
+		return floorDiv(daysBetween(rec.dtstart, t), interval)
+	case Hourly:
+		return floorDiv(int(t.Sub(rec.dtstart)/time.Hour), interval)
+	default:
+		return floorDiv(int(t.Sub(rec.dtstart)/time.Minute), interval)
+	}
+}
+
+// periodStart returns the first instant of period k.
+func (rec *Recurrence) periodStart(k int) time.Time {
+	start := rec.dtstart
+	n := k * rec.rule.Interval
+
+	switch rec.rule.Freq {
+	case Yearly:
+		return time.Date(start.Year()+n, time.January, 1, 0, 0, 0, 0, start.Location())
+	case Monthly:
+		return time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, start.Location())
+	case Weekly:
+		return rec.weekStart(start).AddDate(0, 0, 7*n)
+	case Daily:
+		return midnight(start).AddDate(0, 0, n)
+	case Hourly:
+		return start.Add(time.Duration(n) * time.Hour)
+	default:
+		return start.Add(time.Duration(n) * time.Minute)
+	}
+}
+
+// period returns the sorted occurrences in period k, after BYSETPOS has
+// been applied and anything before dtstart has been dropped.
+func (rec *Recurrence) period(k int) []time.Time {
+	if k < 0 {
+		return nil
+	}
+
+	r := rec.rule
+	start := rec.periodStart(k)
+
+	var candidates []time.Time
+	switch r.Freq {
+	case Hourly, Minutely:
+		if rec.dayMatches(start) {
+			candidates = []time.Time{start}
+		}
+	default:
+		for _, day := range rec.days(start) {
+			candidates = append(candidates, time.Date(day.Year(), day.Month(), day.Day(),
+				rec.dtstart.Hour(), rec.dtstart.Minute(), rec.dtstart.Second(), 0, rec.dtstart.Location()))
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b time.Time) int { return a.Compare(b) })
+	candidates = slices.CompactFunc(candidates, func(a, b time.Time) bool { return a.Equal(b) })
+
+	if len(r.BySetPos) > 0 {
+		var selected []time.Time
+		for _, pos := range r.BySetPos {
+			idx := pos - 1
+			if pos < 0 {
+				idx = len(candidates) + pos
+			}
+			if idx >= 0 && idx < len(candidates) {
+				selected = append(selected, candidates[idx])
+			}
+		}
+		slices.SortFunc(selected, func(a, b time.Time) int { return a.Compare(b) })
+		candidates = slices.CompactFunc(selected, func(a, b time.Time) bool { return a.Equal(b) })
+	}
+
+	occurrences := candidates[:0]
+	for _, candidate := range candidates {
+		if !candidate.Before(rec.dtstart) {
+			occurrences = append(occurrences, candidate)
+		}
+	}
+	return occurrences
+}
+
+// days returns the candidate days of the day-based period starting at
+// start.
+func (rec *Recurrence) days(start time.Time) []time.Time {
+	r := rec.rule
+
+	switch r.Freq {
+	case Daily:
+		if rec.dayMatches(start) {
+			return []time.Time{start}
+		}
+		return nil
+
+	case Weekly:
+		var days []time.Time
+		for i := 0; i < 7; i++ {
+			day := start.AddDate(0, 0, i)
+			if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, int(day.Month())) {
+				continue
+			}
+			if len(r.ByDay) > 0 {
+				if rec.weekdayMatches(day) {
+					days = append(days, day)
+				}
+			} else if day.Weekday() == rec.dtstart.Weekday() {
+				days = append(days, day)
+			}
+		}
+		return days
+
+	case Monthly:
+		if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, int(start.Month())) {
+			return nil
+		}
+		return rec.monthDays(start.Year(), start.Month())
+
+	default:
+		var days []time.Time
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, month := range r.ByMonth {
+				days = append(days, rec.monthDays(start.Year(), time.Month(month))...)
+			}
+		case len(r.ByMonthDay) > 0:
+			for month := time.January; month <= time.December; month++ {
+				days = append(days, rec.monthDays(start.Year(), month)...)
+			}
+		case len(r.ByDay) > 0:
+			first := time.Date(start.Year(), time.January, 1, 0, 0, 0, 0, start.Location())
+			days = expandByDay(r.ByDay, first, first.AddDate(1, 0, -1))
+		default:
+			day := time.Date(start.Year(), rec.dtstart.Month(), rec.dtstart.Day(), 0, 0, 0, 0, start.Location())
+			if day.Day() == rec.dtstart.Day() {
+				days = append(days, day)
+			}
+		}
+		return days
+	}
+}
+
+// monthDays expands BYMONTHDAY and BYDAY within the given month, falling
+// back to the day of month of dtstart.
+func (rec *Recurrence) monthDays(year int, month time.Month) []time.Time {
+	r := rec.rule
+	loc := rec.dtstart.Location()
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	last := first.AddDate(0, 1, -1)
+
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, monthDay := range r.ByMonthDay {
+			day := monthDay
+			if monthDay < 0 {
+				day = last.Day() + monthDay + 1
+			}
+			if day < 1 || day > last.Day() {
+				continue
+			}
+
+			date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+			if len(r.ByDay) == 0 || rec.weekdayMatches(date) {
+				days = append(days, date)
+			}
+		}
+	case len(r.ByDay) > 0:
+		days = expandByDay(r.ByDay, first, last)
+	default:
+		if rec.dtstart.Day() <= last.Day() {
+			days = append(days, time.Date(year, month, rec.dtstart.Day(), 0, 0, 0, 0, loc))
+		}
+	}
+	return days
+}
+
+// dayMatches applies BYMONTH, BYMONTHDAY and BYDAY as filters, as they are
+// for FREQ=DAILY and finer.
+func (rec *Recurrence) dayMatches(t time.Time) bool {
+	r := rec.rule
+
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, int(t.Month())) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+		if !slices.Contains(r.ByMonthDay, t.Day()) && !slices.Contains(r.ByMonthDay, t.Day()-daysInMonth-1) {
+			return false
+		}
+	}
+
+	return len(r.ByDay) == 0 || rec.weekdayMatches(t)
+}
+
+func (rec *Recurrence) weekdayMatches(t time.Time) bool {
+	for _, wd := range rec.rule.ByDay {
+		if wd.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (rec *Recurrence) weekStart(t time.Time) time.Time {
+	day := midnight(t)
+	offset := (int(day.Weekday()) - int(rec.rule.WeekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// expandByDay returns the days between first and last (inclusive) selected
+// by byDay, with ordinals counted within that span.
+func expandByDay(byDay []WeekdayNum, first, last time.Time) []time.Time {
+	var days []time.Time
+	for _, wd := range byDay {
+		var matching []time.Time
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() == wd.Weekday {
+				matching = append(matching, day)
+			}
+		}
+
+		switch {
+		case wd.Ordinal == 0:
+			days = append(days, matching...)
+		case wd.Ordinal > 0 && wd.Ordinal <= len(matching):
+			days = append(days, matching[wd.Ordinal-1])
+		case wd.Ordinal < 0 && -wd.Ordinal <= len(matching):
+			days = append(days, matching[len(matching)+wd.Ordinal])
+		}
+	}
+	return days
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+func daysBetween(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate) / (24 * time.Hour))
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
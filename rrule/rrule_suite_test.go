@@ -0,0 +1,13 @@
+package rrule_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRRule(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RRule Suite")
+}
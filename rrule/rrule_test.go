@@ -0,0 +1,149 @@
+package rrule_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/time-resource/rrule"
+)
+
+var _ = Describe("Parse", func() {
+	DescribeTable("valid rules",
+		func(spec string) {
+			rule, err := rrule.Parse(spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rule.String()).To(Equal(spec))
+		},
+		Entry("monthly by ordinal weekday", "FREQ=MONTHLY;BYDAY=2TU"),
+		Entry("with a prefix", "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"),
+		Entry("with count", "FREQ=DAILY;COUNT=10"),
+		Entry("with until", "FREQ=DAILY;UNTIL=20181231T235959Z"),
+		Entry("with bysetpos", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"),
+	)
+
+	DescribeTable("invalid rules",
+		func(spec string, message string) {
+			_, err := rrule.Parse(spec)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(message))
+		},
+		Entry("missing FREQ", "BYDAY=MO", "FREQ is required"),
+		Entry("unknown FREQ", "FREQ=FORTNIGHTLY", "unsupported rrule FREQ: FORTNIGHTLY"),
+		Entry("unsupported part", "FREQ=DAILY;BYHOUR=9", "unsupported rrule part: BYHOUR"),
+		Entry("bad weekday", "FREQ=MONTHLY;BYDAY=2XX", "unknown weekday: 2XX"),
+		Entry("bad month", "FREQ=YEARLY;BYMONTH=13", "invalid value: 13"),
+		Entry("count and until", "FREQ=DAILY;COUNT=2;UNTIL=20181231", "COUNT and UNTIL are mutually exclusive"),
+		Entry("ordinal with weekly", "FREQ=WEEKLY;BYDAY=2TU", "BYDAY ordinals are only allowed"),
+	)
+})
+
+var _ = Describe("Recurrence", func() {
+	var dtstart time.Time
+
+	BeforeEach(func() {
+		loc, err := time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+
+		dtstart = time.Date(2018, 1, 1, 9, 30, 0, 0, loc)
+	})
+
+	occurrencesUntil := func(spec string, end time.Time) []time.Time {
+		rule, err := rrule.Parse(spec)
+		Expect(err).NotTo(HaveOccurred())
+
+		recurrence := rule.Recurrence(dtstart)
+
+		var dates []time.Time
+		for t := recurrence.Next(dtstart.Add(-time.Nanosecond)); !t.IsZero() && !t.After(end); t = recurrence.Next(t) {
+			dates = append(dates, t)
+		}
+		return dates
+	}
+
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2018, month, day, 9, 30, 0, 0, dtstart.Location())
+	}
+
+	It("expands ordinal weekdays within each month", func() {
+		Expect(occurrencesUntil("FREQ=MONTHLY;BYDAY=2TU", date(4, 30))).To(Equal([]time.Time{
+			date(1, 9), date(2, 13), date(3, 13), date(4, 10),
+		}))
+	})
+
+	It("supports the last weekday of the month", func() {
+		Expect(occurrencesUntil("FREQ=MONTHLY;BYDAY=-1FR", date(3, 31))).To(Equal([]time.Time{
+			date(1, 26), date(2, 23), date(3, 30),
+		}))
+	})
+
+	It("supports negative month days", func() {
+		Expect(occurrencesUntil("FREQ=MONTHLY;BYMONTHDAY=1,-1", date(2, 28))).To(Equal([]time.Time{
+			date(1, 1), date(1, 31), date(2, 1), date(2, 28),
+		}))
+	})
+
+	It("selects with BYSETPOS", func() {
+		// last weekday of each month
+		Expect(occurrencesUntil("FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", date(3, 31))).To(Equal([]time.Time{
+			date(1, 31), date(2, 28), date(3, 30),
+		}))
+	})
+
+	It("honors INTERVAL for weekly rules", func() {
+		Expect(occurrencesUntil("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", date(1, 31))).To(Equal([]time.Time{
+			date(1, 1), date(1, 5), date(1, 15), date(1, 19), date(1, 29),
+		}))
+	})
+
+	It("limits yearly rules with BYMONTH", func() {
+		Expect(occurrencesUntil("FREQ=YEARLY;BYMONTH=3,9;BYDAY=1MO", date(12, 31))).To(Equal([]time.Time{
+			date(3, 5), date(9, 3),
+		}))
+	})
+
+	It("stops after COUNT occurrences", func() {
+		Expect(occurrencesUntil("FREQ=DAILY;COUNT=3", date(12, 31))).To(Equal([]time.Time{
+			date(1, 1), date(1, 2), date(1, 3),
+		}))
+	})
+
+	It("stops after UNTIL", func() {
+		Expect(occurrencesUntil("FREQ=DAILY;UNTIL=20180103", date(12, 31))).To(Equal([]time.Time{
+			date(1, 1), date(1, 2), date(1, 3),
+		}))
+	})
+
+	It("keeps the wall-clock time across DST", func() {
+		Expect(occurrencesUntil("FREQ=WEEKLY;BYDAY=SU", date(3, 18))[9:]).To(Equal([]time.Time{
+			date(3, 11), date(3, 18),
+		}))
+	})
+
+	Describe("Prev", func() {
+		It("returns the latest occurrence at or before the given time", func() {
+			rule, err := rrule.Parse("FREQ=MONTHLY;BYDAY=2TU")
+			Expect(err).NotTo(HaveOccurred())
+
+			recurrence := rule.Recurrence(dtstart)
+			Expect(recurrence.Prev(date(3, 1))).To(Equal(date(2, 13)))
+			Expect(recurrence.Prev(date(2, 13))).To(Equal(date(2, 13)))
+		})
+
+		It("counts from DTSTART when COUNT is set", func() {
+			rule, err := rrule.Parse("FREQ=MONTHLY;BYDAY=2TU;COUNT=2")
+			Expect(err).NotTo(HaveOccurred())
+
+			recurrence := rule.Recurrence(dtstart)
+			Expect(recurrence.Prev(date(12, 1))).To(Equal(date(2, 13)))
+		})
+
+		It("returns the zero time before DTSTART", func() {
+			rule, err := rrule.Parse("FREQ=DAILY")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(rule.Recurrence(dtstart).Prev(dtstart.Add(-time.Hour)).IsZero()).To(BeTrue())
+		})
+	})
+})
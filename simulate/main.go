@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	resource "github.com/concourse/time-resource"
+	"github.com/concourse/time-resource/simulator"
+)
+
+const timeFormat = "Mon 2006-01-02 15:04:05 MST"
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: "+os.Args[0]+" -start time -end time [-every duration] [source.yml]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Replays the checks of a source's schedule and lists the versions they")
+		fmt.Fprintln(os.Stderr, "would produce. The source is read as JSON or YAML from the given file, or")
+		fmt.Fprintln(os.Stderr, "from stdin.")
+		fmt.Fprintln(os.Stderr)
+		flag.PrintDefaults()
+	}
+
+	start := flag.String("start", "", "first check, as an RFC 3339 time or a date in the source's location")
+	end := flag.String("end", "", "stop checking before this RFC 3339 time, or after this date in the source's location")
+	every := flag.Duration("every", simulator.DEFAULT_CHECK_EVERY, "time between checks")
+	flag.Parse()
+
+	if *start == "" || *end == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var input io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		file, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "opening source:", err.Error())
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		input = file
+	}
+
+	source, err := resource.ReadSource(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error:", err.Error())
+		os.Exit(1)
+	}
+
+	loc := time.UTC
+	if source.Location != nil {
+		loc = (*time.Location)(source.Location)
+	}
+
+	simulation := simulator.Simulation{Source: source, CheckEvery: *every}
+
+	simulation.Start, err = parseTime(*start, loc, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error:", err.Error())
+		os.Exit(1)
+	}
+
+	simulation.End, err = parseTime(*end, loc, true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error:", err.Error())
+		os.Exit(1)
+	}
+
+	report, err := simulation.Run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "running simulation:", err.Error())
+		os.Exit(1)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(table, "UTC\t%s\tReason\n", loc)
+	for _, version := range report.Versions {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", version.Time.UTC().Format(timeFormat), version.Time.In(loc).Format(timeFormat), version.Reason)
+	}
+	table.Flush()
+
+	fmt.Println()
+
+	table = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, "Day\tVersions")
+	for _, day := range report.Days {
+		fmt.Fprintf(table, "%s\t%d\n", day.Date.Format("Mon 2006-01-02"), day.Count)
+	}
+	table.Flush()
+
+	fmt.Println()
+
+	fmt.Printf("%d versions\n", len(report.Versions))
+	if len(report.Versions) > 1 {
+		fmt.Printf("shortest gap: %s, from %s\n", report.Shortest.Duration(), report.Shortest.From.In(loc).Format(timeFormat))
+		fmt.Printf("longest gap: %s, from %s\n", report.Longest.Duration(), report.Longest.From.In(loc).Format(timeFormat))
+	}
+
+	for _, anomaly := range report.Anomalies {
+		fmt.Println(anomaly)
+	}
+}
+
+// parseTime parses an RFC 3339 time, or a date in loc. A date is taken to be
+// its start, or the end of it if end is set.
+func parseTime(value string, loc *time.Location, end bool) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %s, must be an RFC 3339 time or a date", value)
+	}
+
+	if end {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return date, nil
+}
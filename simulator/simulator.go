@@ -0,0 +1,281 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	resource "github.com/concourse/time-resource"
+	"github.com/concourse/time-resource/lord"
+	"github.com/concourse/time-resource/models"
+)
+
+// DEFAULT_CHECK_EVERY is how often Concourse checks a resource by default.
+const DEFAULT_CHECK_EVERY = time.Minute
+
+// Simulation replays the checks Concourse would run against a source between
+// Start and End, every CheckEvery, feeding each check the version produced by
+// the one before.
+type Simulation struct {
+	Source     models.Source
+	Start      time.Time
+	End        time.Time
+	CheckEvery time.Duration
+}
+
+// Version is a version produced by a simulated check.
+type Version struct {
+	Time time.Time
+	// CheckedAt is the time of the check that produced the version
+	CheckedAt time.Time
+	// Reason is why the check produced a version, if it decided so from the
+	// schedule at the time of the check, as opposed to e.g. initial_version.
+	// It is empty for versions caught up on with catch_up.
+	Reason lord.Reason
+}
+
+// Day is the number of versions produced on a calendar day in the source's
+// location. Date is midnight of that day.
+type Day struct {
+	Date  time.Time
+	Count int
+}
+
+// Gap is the time between two consecutive versions.
+type Gap struct {
+	From time.Time
+	To   time.Time
+}
+
+func (gap Gap) Duration() time.Duration {
+	return gap.To.Sub(gap.From)
+}
+
+// Anomaly is a daylight saving transition on a day whose versions differ from
+// those of a neighbouring day.
+type Anomaly struct {
+	// Transition is the instant the location's offset changed
+	Transition time.Time
+	// Count is the number of versions on the day of the transition
+	Count int
+	// Expected is the number of versions on the day before, or on the day
+	// after if the transition is on the first day simulated
+	Expected int
+	// Repeated are the versions on the day of the transition that share
+	// their wall clock time with another version that day
+	Repeated []time.Time
+}
+
+func (anomaly Anomaly) String() string {
+	description := fmt.Sprintf(
+		"DST transition at %s: %d versions, %d on a neighbouring day",
+		anomaly.Transition.Format("2006-01-02 15:04:05 MST"),
+		anomaly.Count,
+		anomaly.Expected,
+	)
+
+	if len(anomaly.Repeated) > 0 {
+		var repeated []string
+		for _, version := range anomaly.Repeated {
+			repeated = append(repeated, version.Format("15:04:05 MST"))
+		}
+		description += "; repeated wall clock times: " + strings.Join(repeated, ", ")
+	}
+
+	return description
+}
+
+// Report is the outcome of a Simulation.
+type Report struct {
+	Versions []Version
+	// Days are every day simulated, including those without versions
+	Days []Day
+	// Shortest and Longest are zero if fewer than two versions were produced
+	Shortest  Gap
+	Longest   Gap
+	Anomalies []Anomaly
+}
+
+// Run checks the source at Start and every CheckEvery until End, as Concourse
+// would, and reports the versions produced.
+func (simulation Simulation) Run() (Report, error) {
+	if !simulation.End.After(simulation.Start) {
+		return Report{}, errors.New("end must be after start")
+	}
+
+	checkEvery := simulation.CheckEvery
+	if checkEvery == 0 {
+		checkEvery = DEFAULT_CHECK_EVERY
+	}
+	if checkEvery < 0 {
+		return Report{}, fmt.Errorf("invalid check frequency: %s, must be positive", checkEvery)
+	}
+
+	err := simulation.Source.Validate()
+	if err != nil {
+		return Report{}, err
+	}
+
+	// the schedule is the same for every check, so its calendars are only
+	// loaded once
+	tl, err := resource.NewTimeLord(simulation.Source)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var versions []Version
+	var previous models.Version
+	for now := simulation.Start; now.Before(simulation.End); now = now.Add(checkEvery) {
+		command := resource.CheckCommand{Clock: resource.FixedClock(now), TimeLord: &tl}
+		response, err := command.Run(models.CheckRequest{
+			Source:  simulation.Source,
+			Version: previous,
+		})
+		if err != nil {
+			return Report{}, fmt.Errorf("checking at %s: %w", now.Format(time.RFC3339), err)
+		}
+
+		// with catch_up, the decision is only for the time of the check, not
+		// the scheduled times caught up on
+		reason := command.Decision.Reason
+		if simulation.Source.CatchUp && !previous.Time.IsZero() {
+			reason = ""
+		}
+
+		for _, version := range response {
+			if !previous.Time.IsZero() && !version.Time.After(previous.Time) {
+				continue
+			}
+
+			versions = append(versions, Version{
+				Time:      version.Time,
+				CheckedAt: now,
+				Reason:    reason,
+			})
+			previous = version
+		}
+	}
+
+	loc := time.UTC
+	if simulation.Source.Location != nil {
+		loc = (*time.Location)(simulation.Source.Location)
+	}
+
+	report := Report{
+		Versions: versions,
+		Days:     countDays(versions, simulation.Start, simulation.End, loc),
+	}
+
+	report.Shortest, report.Longest = gaps(versions)
+	report.Anomalies = anomalies(versions, report.Days, loc)
+
+	return report, nil
+}
+
+// countDays counts the versions on every day from start to end in loc.
+func countDays(versions []Version, start, end time.Time, loc *time.Location) []Day {
+	var days []Day
+	for date := midnight(start.In(loc)); date.Before(end); date = date.AddDate(0, 0, 1) {
+		day := Day{Date: date}
+		for _, version := range versions {
+			if midnight(version.Time.In(loc)).Equal(date) {
+				day.Count++
+			}
+		}
+
+		days = append(days, day)
+	}
+
+	return days
+}
+
+func gaps(versions []Version) (Gap, Gap) {
+	var shortest, longest Gap
+	for i := 1; i < len(versions); i++ {
+		gap := Gap{From: versions[i-1].Time, To: versions[i].Time}
+
+		if i == 1 || gap.Duration() < shortest.Duration() {
+			shortest = gap
+		}
+		if i == 1 || gap.Duration() > longest.Duration() {
+			longest = gap
+		}
+	}
+
+	return shortest, longest
+}
+
+// anomalies returns the daylight saving transitions on the given days which
+// produced a different number of versions than a neighbouring day, or which
+// produced versions at the same wall clock time.
+func anomalies(versions []Version, days []Day, loc *time.Location) []Anomaly {
+	var found []Anomaly
+	for i, day := range days {
+		next := day.Date.AddDate(0, 0, 1)
+
+		transition, ok := transitionBetween(day.Date, next)
+		if !ok {
+			continue
+		}
+
+		anomaly := Anomaly{
+			Transition: transition,
+			Count:      day.Count,
+			Expected:   day.Count,
+		}
+
+		if i > 0 {
+			anomaly.Expected = days[i-1].Count
+		} else if len(days) > 1 {
+			anomaly.Expected = days[1].Count
+		}
+
+		wallClock := map[string]int{}
+		for _, version := range versions {
+			if midnight(version.Time.In(loc)).Equal(day.Date) {
+				wallClock[version.Time.In(loc).Format("15:04:05")]++
+			}
+		}
+
+		for _, version := range versions {
+			inLoc := version.Time.In(loc)
+			if midnight(inLoc).Equal(day.Date) && wallClock[inLoc.Format("15:04:05")] > 1 {
+				anomaly.Repeated = append(anomaly.Repeated, inLoc)
+			}
+		}
+
+		if anomaly.Count != anomaly.Expected || len(anomaly.Repeated) > 0 {
+			found = append(found, anomaly)
+		}
+	}
+
+	return found
+}
+
+// transitionBetween returns the first instant from start to end at which
+// their location's offset changes.
+func transitionBetween(start, end time.Time) (time.Time, bool) {
+	_, startOffset := start.Zone()
+	_, endOffset := end.Zone()
+	if startOffset == endOffset {
+		return time.Time{}, false
+	}
+
+	// the offset is startOffset at low and endOffset at high
+	low, high := start, end
+	for high.Sub(low) > time.Second {
+		mid := low.Add(high.Sub(low) / 2).Truncate(time.Second)
+		if _, offset := mid.Zone(); offset == startOffset {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	return high, true
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,192 @@
+package simulator_test
+
+import (
+	"time"
+
+	"github.com/concourse/time-resource/lord"
+	"github.com/concourse/time-resource/models"
+	"github.com/concourse/time-resource/simulator"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Simulation", func() {
+	var (
+		simulation simulator.Simulation
+		report     simulator.Report
+		err        error
+	)
+
+	BeforeEach(func() {
+		simulation = simulator.Simulation{
+			Start: time.Date(2018, 1, 8, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2018, 1, 11, 0, 0, 0, 0, time.UTC),
+		}
+	})
+
+	JustBeforeEach(func() {
+		report, err = simulation.Run()
+	})
+
+	Context("with a range", func() {
+		BeforeEach(func() {
+			start := models.NewTimeOfDay(time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC))
+			stop := models.NewTimeOfDay(time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC))
+			simulation.Source = models.Source{
+				Start:          &start,
+				Stop:           &stop,
+				InitialVersion: true,
+			}
+		})
+
+		It("produces a version when first checked and once in each range", func() {
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Versions).To(Equal([]simulator.Version{
+				{Time: simulation.Start, CheckedAt: simulation.Start},
+				{Time: date(8, 9, 0), CheckedAt: date(8, 9, 0), Reason: lord.WINDOW_STARTED},
+				{Time: date(9, 9, 0), CheckedAt: date(9, 9, 0), Reason: lord.WINDOW_STARTED},
+				{Time: date(10, 9, 0), CheckedAt: date(10, 9, 0), Reason: lord.WINDOW_STARTED},
+			}))
+		})
+
+		It("counts the versions on each day", func() {
+			Expect(report.Days).To(Equal([]simulator.Day{
+				{Date: date(8, 0, 0), Count: 2},
+				{Date: date(9, 0, 0), Count: 1},
+				{Date: date(10, 0, 0), Count: 1},
+			}))
+		})
+
+		It("reports the shortest and longest gaps between versions", func() {
+			Expect(report.Shortest).To(Equal(simulator.Gap{From: date(8, 0, 0), To: date(8, 9, 0)}))
+			Expect(report.Longest).To(Equal(simulator.Gap{From: date(8, 9, 0), To: date(9, 9, 0)}))
+			Expect(report.Longest.Duration()).To(Equal(24 * time.Hour))
+		})
+
+		It("reports no anomalies", func() {
+			Expect(report.Anomalies).To(BeEmpty())
+		})
+
+		Context("when checked less often", func() {
+			BeforeEach(func() {
+				simulation.CheckEvery = 40 * time.Minute
+			})
+
+			It("produces versions at the first check in each range", func() {
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.Versions).To(HaveLen(4))
+				Expect(report.Versions[1].Time).To(Equal(date(8, 9, 20)))
+				Expect(report.Versions[2].Time).To(Equal(date(9, 9, 20)))
+			})
+		})
+
+		Context("when the range is on some days", func() {
+			BeforeEach(func() {
+				simulation.Source.Days = []models.Weekday{models.Weekday(time.Tuesday)}
+			})
+
+			It("counts days without versions", func() {
+				Expect(report.Days).To(Equal([]simulator.Day{
+					{Date: date(8, 0, 0), Count: 1},
+					{Date: date(9, 0, 0), Count: 1},
+					{Date: date(10, 0, 0), Count: 0},
+				}))
+			})
+		})
+	})
+
+	Context("with catch_up", func() {
+		BeforeEach(func() {
+			interval := models.Interval(time.Hour)
+			simulation.Source = models.Source{Interval: &interval, Aligned: true, CatchUp: true}
+			simulation.CheckEvery = 3 * time.Hour
+		})
+
+		It("produces every version a check catches up on", func() {
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Versions).To(HaveLen(70))
+			Expect(report.Versions[69].Time).To(Equal(date(10, 21, 0)))
+			Expect(report.Versions[1].Time).To(Equal(date(8, 1, 0)))
+			Expect(report.Versions[1].CheckedAt).To(Equal(date(8, 3, 0)))
+			Expect(report.Versions[0].Reason).To(Equal(lord.NO_PREVIOUS_VERSION))
+			Expect(report.Versions[1].Reason).To(BeEmpty())
+			Expect(report.Shortest.Duration()).To(Equal(time.Hour))
+			Expect(report.Longest.Duration()).To(Equal(time.Hour))
+		})
+	})
+
+	Context("with an interval over a daylight saving transition", func() {
+		var loc *time.Location
+
+		BeforeEach(func() {
+			loc, err = time.LoadLocation("America/New_York")
+			Expect(err).NotTo(HaveOccurred())
+
+			interval := models.Interval(time.Hour)
+			location := models.Location(*loc)
+			simulation = simulator.Simulation{
+				Source: models.Source{Interval: &interval, Location: &location},
+				Start:  time.Date(2018, 11, 3, 0, 0, 0, 0, loc),
+				End:    time.Date(2018, 11, 6, 0, 0, 0, 0, loc),
+			}
+		})
+
+		It("counts the longer day", func() {
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Versions).To(HaveLen(73))
+			Expect(report.Days).To(HaveLen(3))
+			Expect(report.Days[1].Count).To(Equal(25))
+		})
+
+		It("reports the transition as an anomaly", func() {
+			Expect(report.Anomalies).To(HaveLen(1))
+
+			anomaly := report.Anomalies[0]
+			Expect(anomaly.Transition).To(BeTemporally("==", time.Date(2018, 11, 4, 6, 0, 0, 0, time.UTC)))
+			Expect(anomaly.Count).To(Equal(25))
+			Expect(anomaly.Expected).To(Equal(24))
+			Expect(anomaly.Repeated).To(HaveLen(2))
+			Expect(anomaly.String()).To(Equal("DST transition at 2018-11-04 01:00:00 EST: 25 versions, 24 on a neighbouring day; repeated wall clock times: 01:00:00 EDT, 01:00:00 EST"))
+		})
+	})
+
+	Context("when the end is not after the start", func() {
+		BeforeEach(func() {
+			simulation.End = simulation.Start
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("end must be after start"))
+		})
+	})
+
+	Context("when the check frequency is negative", func() {
+		BeforeEach(func() {
+			simulation.CheckEvery = -time.Minute
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("invalid check frequency: -1m0s, must be positive"))
+		})
+	})
+
+	Context("when the source is invalid", func() {
+		BeforeEach(func() {
+			simulation.Source = models.Source{IntervalAnchor: models.IntervalAnchor("sometimes")}
+		})
+
+		It("returns the validation error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("invalid interval_anchor"))
+		})
+	})
+})
+
+func date(day, hour, minute int) time.Time {
+	return time.Date(2018, 1, day, hour, minute, 0, 0, time.UTC)
+}
@@ -0,0 +1,81 @@
+package solar
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// julian date of the J2000 epoch, 2000-01-01 12:00 UTC
+	j2000 = 2451545.0
+	// julian date of the unix epoch
+	unixEpoch = 2440587.5
+
+	// sun's altitude at sunrise and sunset, accounting for refraction and
+	// the size of the solar disc
+	horizon = -0.833
+	// obliquity of the ecliptic
+	obliquity = 23.4397
+)
+
+// Sunrise returns the time of sunrise on the date of day at the given
+// latitude and longitude in degrees (north and east positive). It returns
+// false if the sun does not rise that day, as during polar night or the
+// midnight sun.
+func Sunrise(day time.Time, latitude, longitude float64) (time.Time, bool) {
+	transit, hourAngle, ok := solarDay(day, latitude, longitude)
+	if !ok {
+		return time.Time{}, false
+	}
+	return fromJulian(transit - hourAngle/360), true
+}
+
+// Sunset returns the time of sunset on the date of day. See Sunrise.
+func Sunset(day time.Time, latitude, longitude float64) (time.Time, bool) {
+	transit, hourAngle, ok := solarDay(day, latitude, longitude)
+	if !ok {
+		return time.Time{}, false
+	}
+	return fromJulian(transit + hourAngle/360), true
+}
+
+// solarDay returns the julian date of solar noon on the date of day and the
+// hour angle of sunrise and sunset from it, following the sunrise equation.
+func solarDay(day time.Time, latitude, longitude float64) (float64, float64, bool) {
+	noon := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, time.UTC)
+	n := math.Round(toJulian(noon) - j2000)
+
+	meanSolarNoon := n - longitude/360
+
+	anomaly := math.Mod(357.5291+0.98560028*meanSolarNoon, 360)
+	center := 1.9148*sin(anomaly) + 0.0200*sin(2*anomaly) + 0.0003*sin(3*anomaly)
+	eclipticLongitude := math.Mod(anomaly+center+180+102.9372, 360)
+
+	transit := j2000 + meanSolarNoon + 0.0053*sin(anomaly) - 0.0069*sin(2*eclipticLongitude)
+
+	sinDeclination := sin(eclipticLongitude) * sin(obliquity)
+	cosDeclination := math.Cos(math.Asin(sinDeclination))
+
+	cosHourAngle := (sin(horizon) - sin(latitude)*sinDeclination) / (cos(latitude) * cosDeclination)
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return 0, 0, false
+	}
+
+	return transit, math.Acos(cosHourAngle) * 180 / math.Pi, true
+}
+
+func toJulian(t time.Time) float64 {
+	return float64(t.Unix())/86400 + unixEpoch
+}
+
+func fromJulian(j float64) time.Time {
+	return time.Unix(0, int64((j-unixEpoch)*86400*float64(time.Second))).UTC()
+}
+
+func sin(degrees float64) float64 {
+	return math.Sin(degrees * math.Pi / 180)
+}
+
+func cos(degrees float64) float64 {
+	return math.Cos(degrees * math.Pi / 180)
+}
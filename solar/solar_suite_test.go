@@ -0,0 +1,13 @@
+package solar_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSolar(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Solar Suite")
+}
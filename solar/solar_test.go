@@ -0,0 +1,71 @@
+package solar_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/time-resource/solar"
+)
+
+type place struct {
+	latitude  float64
+	longitude float64
+}
+
+var (
+	london  = place{51.5074, -0.1278}
+	newYork = place{40.7128, -74.0060}
+	sydney  = place{-33.8688, 151.2093}
+	tromso  = place{69.6496, 18.9560}
+)
+
+func utc(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+var _ = DescribeTable("Sunrise", func(p place, day time.Time, expected time.Time) {
+	sunrise, found := solar.Sunrise(day, p.latitude, p.longitude)
+	Expect(found).To(BeTrue())
+	Expect(sunrise).To(BeTemporally("~", expected, 2*time.Minute))
+},
+	Entry("London at midsummer", london, utc(2026, time.June, 21, 0, 0), utc(2026, time.June, 21, 3, 43)),
+	Entry("London in winter", london, utc(2018, time.January, 1, 0, 0), utc(2018, time.January, 1, 8, 6)),
+	Entry("New York on new year's day", newYork, utc(2026, time.January, 1, 0, 0), utc(2026, time.January, 1, 12, 20)),
+	Entry("Sydney, which rises on the previous UTC day", sydney, utc(2026, time.March, 20, 0, 0), utc(2026, time.March, 19, 19, 57)),
+)
+
+var _ = DescribeTable("Sunset", func(p place, day time.Time, expected time.Time) {
+	sunset, found := solar.Sunset(day, p.latitude, p.longitude)
+	Expect(found).To(BeTrue())
+	Expect(sunset).To(BeTemporally("~", expected, 2*time.Minute))
+},
+	Entry("London at midsummer", london, utc(2026, time.June, 21, 0, 0), utc(2026, time.June, 21, 20, 21)),
+	Entry("London in winter", london, utc(2018, time.January, 1, 0, 0), utc(2018, time.January, 1, 16, 2)),
+	Entry("New York on new year's day", newYork, utc(2026, time.January, 1, 0, 0), utc(2026, time.January, 1, 21, 38)),
+)
+
+var _ = Describe("the sun above the arctic circle", func() {
+	It("does not rise during polar night", func() {
+		_, found := solar.Sunrise(utc(2026, time.December, 21, 0, 0), tromso.latitude, tromso.longitude)
+		Expect(found).To(BeFalse())
+	})
+
+	It("does not set during the midnight sun", func() {
+		_, found := solar.Sunset(utc(2026, time.June, 21, 0, 0), tromso.latitude, tromso.longitude)
+		Expect(found).To(BeFalse())
+	})
+})
+
+var _ = Describe("the day", func() {
+	It("is the calendar date of the given time in its location", func() {
+		loc, err := time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+
+		late := time.Date(2026, time.January, 1, 23, 0, 0, 0, loc)
+		sunrise, found := solar.Sunrise(late, newYork.latitude, newYork.longitude)
+		Expect(found).To(BeTrue())
+		Expect(sunrise).To(BeTemporally("~", utc(2026, time.January, 1, 12, 20), 2*time.Minute))
+	})
+})